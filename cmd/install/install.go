@@ -0,0 +1,54 @@
+package install
+
+import (
+	"github.com/puppetlabs/pdkgo/internal/pkg/pct"
+	"github.com/puppetlabs/pdkgo/internal/pkg/utils"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	localTemplateCache string
+	force              bool
+)
+
+func CreateCommand() *cobra.Command {
+	tmp := &cobra.Command{
+		Use:     "install <source> [flags]",
+		Short:   "Installs a template from an archive, directory or git repository",
+		Long:    `Installs a template into the template cache from a .tar.gz archive, a local directory or a git repository URL`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: preExecute,
+		RunE:    execute,
+	}
+
+	tmp.Flags().SortFlags = false
+
+	tmp.Flags().BoolVarP(&force, "force", "f", false, "overwrite an existing install of the same template version")
+	tmp.Flags().StringVar(&localTemplateCache, "templatepath", "", "location of installed templates")
+	return tmp
+}
+
+func preExecute(cmd *cobra.Command, args []string) error {
+	templatePath, err := utils.GetTemplatePath(cmd)
+	if err != nil {
+		return err
+	}
+
+	localTemplateCache = templatePath
+	return nil
+}
+
+func execute(cmd *cobra.Command, args []string) error {
+	log.Trace().Msgf("Template path: %v", localTemplateCache)
+	log.Trace().Msgf("Source: %v", args[0])
+
+	installed, err := pct.Install(args[0], localTemplateCache, force)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msgf("Installed: %v", installed)
+	return nil
+}
@@ -1,9 +1,8 @@
 package new
 
 import (
+	"bufio"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/puppetlabs/pdkgo/internal/pkg/pct"
@@ -22,11 +21,21 @@ var (
 	listTemplates        bool
 	targetName           string
 	targetOutput         string
+	interactive          bool
+	overrides            []string
+	answersFile          string
+	writeAnswers         bool
+	dryRun               bool
+	onConflict           string
+	showDiff             bool
+	strict               bool
+	noHooks              bool
+	trustHooks           bool
 )
 
 func CreateCommand() *cobra.Command {
 	tmp := &cobra.Command{
-		Use:               "new <template> [flags]",
+		Use:               "new <template>[@version] [flags]",
 		Short:             "Creates a Puppet project or other artifact based on a template",
 		Long:              `Creates a Puppet project or other artifact based on a template`,
 		Args:              validateArgCount,
@@ -39,6 +48,19 @@ func CreateCommand() *cobra.Command {
 
 	tmp.Flags().StringVarP(&targetName, "name", "n", "", "the name for the created output.")
 	tmp.Flags().StringVarP(&targetOutput, "output", "o", "", "location to place the generated output.")
+	tmp.Flags().BoolVar(&interactive, "interactive", false, "prompt for the value of each template parameter")
+	tmp.Flags().StringVar(&answersFile, "answers", "", "a YAML or JSON file of answers for the template variables")
+	tmp.Flags().BoolVar(&writeAnswers, "write-answers", false, "write the resolved template variables to "+pct.AnswersFileName+" in the output directory")
+	tmp.Flags().BoolVar(&dryRun, "dry-run", false, "show the files that would be created or overwritten without writing anything")
+	tmp.Flags().BoolVar(&strict, "strict", false, "fail on any reference to an undefined template variable")
+	tmp.Flags().BoolVar(&showDiff, "diff", false, "show a unified diff of the template output against the existing files without writing anything, exiting non-zero when they differ")
+	tmp.Flags().StringVar(&onConflict, "on-conflict", pct.ConflictFail, "how to handle existing files that differ from the template output: "+strings.Join(pct.ConflictPolicies, ", "))
+	tmp.RegisterFlagCompletionFunc("on-conflict", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) { //nolint:errcheck
+		return utils.Find(pct.ConflictPolicies, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+	})
+	tmp.Flags().BoolVar(&noHooks, "no-hooks", false, "don't run the commands the template declares to run after it is deployed")
+	tmp.Flags().BoolVar(&trustHooks, "trust-hooks", false, "run the template's hooks without asking for confirmation")
+	tmp.Flags().StringArrayVar(&overrides, "set", []string{}, "override a template variable, e.g. --set puppet_module.license=MIT (can be repeated)")
 
 	tmp.Flags().BoolVarP(&listTemplates, "list", "l", false, "list templates")
 	tmp.RegisterFlagCompletionFunc("list", flagCompletion) //nolint:errcheck
@@ -56,18 +78,16 @@ func CreateCommand() *cobra.Command {
 	})
 
 	tmp.Flags().StringVar(&localTemplateCache, "templatepath", "", "location of installed templates")
-	viper.BindPFlag("templatepath", tmp.Flags().Lookup("templatepath")) //nolint:errcheck
 	return tmp
 }
 
 func preExecute(cmd *cobra.Command, args []string) error {
-	defaultTemplatePath, err := getDefaultTemplatePath()
+	templatePath, err := utils.GetTemplatePath(cmd)
 	if err != nil {
 		return err
 	}
 
-	viper.SetDefault("templatepath", defaultTemplatePath)
-	localTemplateCache = viper.GetString("templatepath")
+	localTemplateCache = templatePath
 	return nil
 }
 
@@ -107,29 +127,19 @@ func completeName(cache string, match string) []string {
 	tmpls, _ := pct.List(cache, "")
 	var names []string
 	for _, tmpl := range tmpls {
-		if strings.HasPrefix(tmpl.Id, match) {
+		if tmpl.Default && strings.HasPrefix(tmpl.Id, match) {
 			m := tmpl.Id + "\t" + tmpl.Display
 			names = append(names, m)
 		}
+		ref := tmpl.Id + "@" + tmpl.Version
+		if strings.Contains(match, "@") && strings.HasPrefix(ref, match) {
+			m := ref + "\t" + tmpl.Display
+			names = append(names, m)
+		}
 	}
 	return names
 }
 
-func getApplicationInfo(appVersionString string) pct.PDKInfo {
-	info := strings.Split(appVersionString, "\n")[0]
-	appInfo := strings.Split(info, " ")
-	ver := appInfo[1]
-	commit := appInfo[2]
-	buildDate := appInfo[3]
-
-	pdkInfo := pct.PDKInfo{
-		Version:   ver,
-		Commit:    commit,
-		BuildDate: buildDate,
-	}
-	return pdkInfo
-}
-
 func execute(cmd *cobra.Command, args []string) error {
 	log.Trace().Msg("Run")
 	log.Trace().Msgf("Template path: %v", localTemplateCache)
@@ -154,6 +164,12 @@ func execute(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return err
 		}
+		if len(pctData.Template.Parameters) > 0 {
+			log.Debug().Msgf("Template Parameters: %v", pctData.Template.Parameters)
+			fmt.Printf("%s\n", pct.DisplayParameters(pctData.Template.Parameters, format))
+			return nil
+		}
+
 		log.Debug().Msgf("Template Defaults: %v", pctData.Defaults)
 		defaultString := pct.DisplayDefaults(pctData.Defaults, format)
 		fmt.Printf("%s\n", defaultString)
@@ -167,31 +183,73 @@ func execute(cmd *cobra.Command, args []string) error {
 	}
 
 	appVersionString := cmd.Parent().Version
-	pdkInfo := getApplicationInfo(appVersionString)
+	pdkInfo := utils.GetApplicationInfo(appVersionString)
 
-	deployed := pct.Deploy(pct.DeployInfo{
+	overrideValues, err := pct.ParseOverrides(overrides)
+	if err != nil {
+		return err
+	}
+
+	// prompts for parameters and for running hooks share a reader, as each
+	// may read ahead of the line it needs. Prompts and hook output go to
+	// stderr, keeping stdout for the --format output
+	in := bufio.NewReader(cmd.InOrStdin())
+	deployInfo := pct.DeployInfo{
 		SelectedTemplate: selectedTemplate,
 		TemplateCache:    localTemplateCache,
 		TargetOutputDir:  targetOutput,
 		TargetName:       targetName,
 		PdkInfo:          pdkInfo,
-	})
+		Overrides:        overrideValues,
+		WriteAnswers:     writeAnswers,
+		DryRun:           dryRun,
+		OnConflict:       onConflict,
+		Strict:           strict,
+		NoHooks:          noHooks,
+		TrustHooks:       trustHooks,
+		TrustedTemplates: viper.GetStringSlice("trusted_templates"),
+		In:               in,
+		Out:              cmd.ErrOrStderr(),
+	}
 
-	err = pct.FormatDeployment(deployed, format)
-	if err != nil {
-		return err
+	if answersFile != "" {
+		answers, err := pct.ReadAnswers(answersFile)
+		if err != nil {
+			return err
+		}
+		deployInfo.Answers = answers
 	}
 
-	return nil
-}
+	if interactive {
+		answers, err := pct.PromptParameters(deployInfo, in, cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+		deployInfo.Answers = answers
+	}
 
-func getDefaultTemplatePath() (string, error) {
-	execDir, err := os.Executable()
-	if err != nil {
-		return "", err
+	if showDiff {
+		changed, err := pct.Diff(deployInfo, cmd.OutOrStdout())
+		if err != nil {
+			return err
+		}
+		if changed {
+			// the diff is the output, so only the exit code signals a change
+			cmd.SilenceUsage = true
+			return fmt.Errorf("The template output differs from the existing files")
+		}
+		return nil
+	}
+
+	deployed, err := pct.Deploy(deployInfo)
+	if err != nil && len(deployed.Files) == 0 {
+		return err
+	}
+
+	// a failed hook is reported after the files that were deployed
+	if ferr := pct.FormatDeployment(deployed, format); ferr != nil {
+		return ferr
 	}
 
-	defaultTemplatePath := filepath.Join(filepath.Dir(execDir), "templates")
-	log.Trace().Msgf("Default template path: %v", defaultTemplatePath)
-	return defaultTemplatePath, nil
+	return err
 }
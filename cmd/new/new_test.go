@@ -36,6 +36,69 @@ func TestCreateCommand(t *testing.T) {
 			out:     "",
 			wantErr: false,
 		},
+		{
+			name:    "executes without error for interactive flag",
+			args:    []string{"full-project", "--interactive"},
+			f:       nullFunction,
+			out:     "",
+			wantErr: false,
+		},
+		{
+			name:    "executes without error for repeated set flags",
+			args:    []string{"full-project", "--set", "puppet_module.license=MIT", "--set", "puppet_module.version=1.0.0"},
+			f:       nullFunction,
+			out:     "",
+			wantErr: false,
+		},
+		{
+			name:    "executes without error for answers flags",
+			args:    []string{"full-project", "--answers", "answers.yml", "--write-answers"},
+			f:       nullFunction,
+			out:     "",
+			wantErr: false,
+		},
+		{
+			name:    "executes without error for dry-run flag",
+			args:    []string{"full-project", "--dry-run"},
+			f:       nullFunction,
+			out:     "",
+			wantErr: false,
+		},
+		{
+			name:    "executes without error for on-conflict flag",
+			args:    []string{"full-project", "--on-conflict", "backup"},
+			f:       nullFunction,
+			out:     "",
+			wantErr: false,
+		},
+		{
+			name:    "executes without error for diff flag",
+			args:    []string{"full-project", "--diff"},
+			f:       nullFunction,
+			out:     "",
+			wantErr: false,
+		},
+		{
+			name:    "executes without error for strict flag",
+			args:    []string{"full-project", "--strict"},
+			f:       nullFunction,
+			out:     "",
+			wantErr: false,
+		},
+		{
+			name:    "executes without error for no-hooks flag",
+			args:    []string{"full-project", "--no-hooks"},
+			f:       nullFunction,
+			out:     "",
+			wantErr: false,
+		},
+		{
+			name:    "executes without error for trust-hooks flag",
+			args:    []string{"full-project", "--trust-hooks"},
+			f:       nullFunction,
+			out:     "",
+			wantErr: false,
+		},
 		{
 			name:    "executes with error for invalid flag",
 			args:    []string{"--foo"},
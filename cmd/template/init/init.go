@@ -0,0 +1,56 @@
+package init
+
+import (
+	"strings"
+
+	"github.com/puppetlabs/pdkgo/internal/pkg/pct"
+	"github.com/puppetlabs/pdkgo/internal/pkg/utils"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	templateType string
+	targetOutput string
+)
+
+func CreateCommand() *cobra.Command {
+	tmp := &cobra.Command{
+		Use:   "init <id> [flags]",
+		Short: "Creates the skeleton of a new template",
+		Long: `Creates a new template in a directory named <id>, with a pct-config.yml, a content
+directory with an example template file, a README and a test fixture, ready to be
+checked with 'pct template validate'`,
+		Args: cobra.ExactArgs(1),
+		RunE: execute,
+	}
+
+	tmp.Flags().SortFlags = false
+
+	tmp.Flags().StringVar(&templateType, "type", "project", "the type of template: "+strings.Join(pct.TemplateTypes, " or "))
+	tmp.RegisterFlagCompletionFunc("type", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) { //nolint:errcheck
+		return utils.Find(pct.TemplateTypes, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+	})
+	tmp.Flags().StringVarP(&targetOutput, "output", "o", "", "location to create the template directory in.")
+	return tmp
+}
+
+func execute(cmd *cobra.Command, args []string) error {
+	log.Trace().Msgf("Template: %v", args[0])
+
+	created, err := pct.InitTemplate(pct.InitInfo{
+		Id:              args[0],
+		Type:            templateType,
+		TargetOutputDir: targetOutput,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, f := range created.Files {
+		log.Info().Msgf("Created: %s", f)
+	}
+	log.Info().Msgf("Created the template '%s' in %s", args[0], created.Path)
+	return nil
+}
@@ -0,0 +1,42 @@
+package path
+
+import (
+	"fmt"
+
+	"github.com/puppetlabs/pdkgo/internal/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	localTemplateCache string
+)
+
+func CreateCommand() *cobra.Command {
+	tmp := &cobra.Command{
+		Use:     "path [flags]",
+		Short:   "Displays the location of installed templates",
+		Long:    `Displays the location of installed templates, as set by --templatepath, the templatepath config setting or the default template location`,
+		Args:    cobra.NoArgs,
+		PreRunE: preExecute,
+		RunE:    execute,
+	}
+
+	tmp.Flags().StringVar(&localTemplateCache, "templatepath", "", "location of installed templates")
+	return tmp
+}
+
+func preExecute(cmd *cobra.Command, args []string) error {
+	templatePath, err := utils.GetTemplatePath(cmd)
+	if err != nil {
+		return err
+	}
+
+	localTemplateCache = templatePath
+	return nil
+}
+
+func execute(cmd *cobra.Command, args []string) error {
+	fmt.Println(localTemplateCache)
+	return nil
+}
@@ -0,0 +1,50 @@
+package prune
+
+import (
+	"github.com/puppetlabs/pdkgo/internal/pkg/pct"
+	"github.com/puppetlabs/pdkgo/internal/pkg/utils"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	localTemplateCache string
+)
+
+func CreateCommand() *cobra.Command {
+	tmp := &cobra.Command{
+		Use:     "prune [flags]",
+		Short:   "Removes all but the newest version of each installed template",
+		Long:    `Removes all but the newest version of each installed template from the template cache`,
+		Args:    cobra.NoArgs,
+		PreRunE: preExecute,
+		RunE:    execute,
+	}
+
+	tmp.Flags().StringVar(&localTemplateCache, "templatepath", "", "location of installed templates")
+	return tmp
+}
+
+func preExecute(cmd *cobra.Command, args []string) error {
+	templatePath, err := utils.GetTemplatePath(cmd)
+	if err != nil {
+		return err
+	}
+
+	localTemplateCache = templatePath
+	return nil
+}
+
+func execute(cmd *cobra.Command, args []string) error {
+	log.Trace().Msgf("Template path: %v", localTemplateCache)
+
+	removed, err := pct.Prune(localTemplateCache)
+	for _, r := range removed {
+		log.Info().Msgf("Removed: %v", r)
+	}
+	if err == nil && len(removed) == 0 {
+		log.Info().Msg("Nothing to prune")
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package remove
+
+import (
+	"github.com/puppetlabs/pdkgo/internal/pkg/pct"
+	"github.com/puppetlabs/pdkgo/internal/pkg/utils"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	localTemplateCache string
+)
+
+func CreateCommand() *cobra.Command {
+	tmp := &cobra.Command{
+		Use:     "remove <id>[@version] [flags]",
+		Short:   "Removes an installed template",
+		Long:    `Removes an installed template from the template cache. Every installed version is removed unless a version is given`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: preExecute,
+		RunE:    execute,
+	}
+
+	tmp.Flags().StringVar(&localTemplateCache, "templatepath", "", "location of installed templates")
+	return tmp
+}
+
+func preExecute(cmd *cobra.Command, args []string) error {
+	templatePath, err := utils.GetTemplatePath(cmd)
+	if err != nil {
+		return err
+	}
+
+	localTemplateCache = templatePath
+	return nil
+}
+
+func execute(cmd *cobra.Command, args []string) error {
+	id, version := pct.ParseTemplateReference(args[0])
+	log.Trace().Msgf("Template path: %v", localTemplateCache)
+	log.Trace().Msgf("Removing template: %v version: %v", id, version)
+
+	removed, err := pct.Remove(localTemplateCache, id, version)
+	for _, r := range removed {
+		log.Info().Msgf("Removed: %v", r)
+	}
+	return err
+}
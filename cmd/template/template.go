@@ -0,0 +1,15 @@
+package template
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func CreateCommand() *cobra.Command {
+	tmp := &cobra.Command{
+		Use:   "template <subcommand> [flags]",
+		Short: "Manage installed templates",
+		Long:  `Inspect and manage the templates installed in the template cache`,
+	}
+
+	return tmp
+}
@@ -0,0 +1,53 @@
+package validate
+
+import (
+	"fmt"
+
+	"github.com/puppetlabs/pdkgo/internal/pkg/pct"
+	"github.com/puppetlabs/pdkgo/internal/pkg/utils"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	format string
+)
+
+func CreateCommand() *cobra.Command {
+	tmp := &cobra.Command{
+		Use:   "validate <path> [flags]",
+		Short: "Checks a template for problems",
+		Long: `Checks the pct-config.yml, template files and file names of the template in <path> for
+problems that would otherwise only be found when the template is used, reporting each one
+with its file and line`,
+		Args: cobra.ExactArgs(1),
+		RunE: execute,
+	}
+
+	tmp.Flags().StringVar(&format, "format", "text", "display output in text or json format")
+	tmp.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) { //nolint:errcheck
+		var formats = []string{"text", "json"}
+		return utils.Find(formats, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+	})
+	return tmp
+}
+
+func execute(cmd *cobra.Command, args []string) error {
+	log.Trace().Msgf("Template: %v", args[0])
+
+	problems, err := pct.LintTemplate(args[0])
+	if err != nil {
+		return err
+	}
+
+	err = pct.FormatTemplateProblems(cmd.OutOrStdout(), problems, format)
+	if err != nil {
+		return err
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("Found %d problem(s) in '%s'", len(problems), args[0])
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package upgrade
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/puppetlabs/pdkgo/internal/pkg/pct"
+	"github.com/puppetlabs/pdkgo/internal/pkg/utils"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	localTemplateCache string
+	format             string
+	targetOutput       string
+	overrides          []string
+	dryRun             bool
+	strict             bool
+)
+
+func CreateCommand() *cobra.Command {
+	tmp := &cobra.Command{
+		Use:   "upgrade [<template>[@version]] [flags]",
+		Short: "Upgrades content generated from a template to a newer version of the template",
+		Long: `Upgrades content generated by 'pct new' to a newer version of the template, using the
+template variables recorded when it was generated. Changes made by the template are merged
+with local changes, and conflicting changes are written with conflict markers`,
+		Args:    cobra.MaximumNArgs(1),
+		PreRunE: preExecute,
+		RunE:    execute,
+	}
+
+	tmp.Flags().SortFlags = false
+
+	tmp.Flags().StringVarP(&targetOutput, "output", "o", "", "location of the generated content to upgrade.")
+	tmp.Flags().StringArrayVar(&overrides, "set", []string{}, "override a template variable, e.g. --set puppet_module.license=MIT (can be repeated)")
+	tmp.Flags().BoolVar(&dryRun, "dry-run", false, "show what would happen to each file without writing anything")
+	tmp.Flags().BoolVar(&strict, "strict", false, "fail on any reference to an undefined template variable")
+
+	tmp.Flags().StringVar(&format, "format", "table", "display output in table or json format")
+	tmp.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) { //nolint:errcheck
+		var formats = []string{"table", "json"}
+		return utils.Find(formats, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+	})
+
+	tmp.Flags().StringVar(&localTemplateCache, "templatepath", "", "location of installed templates")
+	return tmp
+}
+
+func preExecute(cmd *cobra.Command, args []string) error {
+	templatePath, err := utils.GetTemplatePath(cmd)
+	if err != nil {
+		return err
+	}
+
+	localTemplateCache = templatePath
+	return nil
+}
+
+func execute(cmd *cobra.Command, args []string) error {
+	log.Trace().Msgf("Template path: %v", localTemplateCache)
+
+	overrideValues, err := pct.ParseOverrides(overrides)
+	if err != nil {
+		return err
+	}
+
+	upgradeInfo := pct.UpgradeInfo{
+		TemplateCache:   localTemplateCache,
+		TargetOutputDir: targetOutput,
+		Overrides:       overrideValues,
+		DryRun:          dryRun,
+		Strict:          strict,
+	}
+	if len(args) == 1 {
+		upgradeInfo.SelectedTemplate = args[0]
+	}
+	if cmd.Parent() != nil {
+		upgradeInfo.PdkInfo = utils.GetApplicationInfo(cmd.Parent().Version)
+	}
+
+	upgraded, err := pct.Upgrade(upgradeInfo)
+	if err != nil {
+		return err
+	}
+
+	err = pct.FormatUpgrade(upgraded, format)
+	if err != nil {
+		return err
+	}
+
+	if conflicts := upgraded.Conflicts(); len(conflicts) > 0 {
+		return fmt.Errorf("%d file(s) have conflicts, resolve the conflict markers before committing the upgrade:\n  - %s", len(conflicts), strings.Join(conflicts, "\n  - "))
+	}
+	return nil
+}
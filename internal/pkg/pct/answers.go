@@ -0,0 +1,57 @@
+package pct
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+// AnswersFileName is the name of the file Deploy writes the resolved template
+// data to, when DeployInfo.WriteAnswers is set
+const AnswersFileName = ".pct-answers.yml"
+
+// ReadAnswers reads an answers file in YAML or JSON format, returning its
+// values for use as DeployInfo.Answers
+func ReadAnswers(answersFile string) (map[string]interface{}, error) {
+	if _, err := os.Stat(answersFile); err != nil {
+		return nil, fmt.Errorf("Unable to read answers file '%s': %s", answersFile, err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(answersFile)
+	switch strings.ToLower(filepath.Ext(answersFile)) {
+	case ".json":
+		v.SetConfigType("json")
+	default:
+		v.SetConfigType("yaml")
+	}
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("Unable to read answers file '%s': %s", answersFile, err)
+	}
+	log.Trace().Msgf("Using answers file: %v", v.ConfigFileUsed())
+
+	return v.AllSettings(), nil
+}
+
+// mergeAnswers deep merges overlay on top of base, returning a new map
+func mergeAnswers(base map[string]interface{}, overlay map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overlay {
+		nested, isMap := v.(map[string]interface{})
+		existing, wasMap := merged[k].(map[string]interface{})
+		if isMap && wasMap {
+			merged[k] = mergeAnswers(existing, nested)
+			continue
+		}
+		merged[k] = v
+	}
+	return merged
+}
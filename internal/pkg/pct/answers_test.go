@@ -0,0 +1,120 @@
+package pct
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadAnswers(t *testing.T) {
+	tests := []struct {
+		name        string
+		answersFile string
+		want        map[string]interface{}
+		wantErr     bool
+	}{
+		{
+			name:        "reads a YAML answers file",
+			answersFile: "testdata/answers/answers.yml",
+			want: map[string]interface{}{
+				"puppet_module": map[string]interface{}{
+					"summary": "Answered from YAML",
+					"license": "MIT",
+				},
+			},
+		},
+		{
+			name:        "reads a JSON answers file",
+			answersFile: "testdata/answers/answers.json",
+			want: map[string]interface{}{
+				"puppet_module": map[string]interface{}{
+					"summary": "Answered from JSON",
+					"license": "MIT",
+				},
+			},
+		},
+		{
+			name:        "returns an error for a missing answers file",
+			answersFile: "testdata/answers/notthere.yml",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadAnswers(tt.answersFile)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadAnswers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDeployWriteAnswers(t *testing.T) {
+	tmp := t.TempDir()
+	answers, err := ReadAnswers("testdata/answers/answers.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deployed, err := Deploy(DeployInfo{
+		SelectedTemplate: "param-project",
+		TemplateCache:    "testdata/examples",
+		TargetOutputDir:  filepath.Join(tmp, "original"),
+		TargetName:       "woo",
+		Answers:          answers,
+		Overrides:        map[string]string{"puppet_module.version": "2.0.0"},
+		WriteAnswers:     true,
+	})
+	assert.NoError(t, err)
+
+	answersFile := filepath.Join(tmp, "original", "woo", AnswersFileName)
+	assert.Contains(t, deployedPaths(deployed), answersFile)
+
+	written, err := ReadAnswers(answersFile)
+	assert.NoError(t, err)
+	assert.NotContains(t, written, "template")
+	assert.Equal(t, "woo", written["pct_name"])
+
+	// replaying the written answers reproduces the original output
+	_, err = Deploy(DeployInfo{
+		SelectedTemplate: "param-project",
+		TemplateCache:    "testdata/examples",
+		TargetOutputDir:  filepath.Join(tmp, "replay"),
+		TargetName:       "woo",
+		Answers:          written,
+	})
+	assert.NoError(t, err)
+
+	original, _ := os.ReadFile(filepath.Join(tmp, "original", "woo", "README.md"))
+	replayed, _ := os.ReadFile(filepath.Join(tmp, "replay", "woo", "README.md"))
+	assert.Equal(t, "Answered from YAML (MIT 2.0.0)\n", string(original))
+	assert.Equal(t, string(original), string(replayed))
+}
+
+func Test_mergeAnswers(t *testing.T) {
+	base := map[string]interface{}{
+		"puppet_module": map[string]interface{}{
+			"summary": "base",
+			"license": "MIT",
+		},
+		"other": "base",
+	}
+	overlay := map[string]interface{}{
+		"puppet_module": map[string]interface{}{
+			"summary": "overlay",
+		},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"puppet_module": map[string]interface{}{
+			"summary": "overlay",
+			"license": "MIT",
+		},
+		"other": "base",
+	}, mergeAnswers(base, overlay))
+	assert.Equal(t, "base", base["puppet_module"].(map[string]interface{})["summary"])
+}
@@ -0,0 +1,129 @@
+package pct
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// ParseTemplateReference splits a template reference in the form
+// <id>[@<version>] into its id and version
+func ParseTemplateReference(ref string) (id string, version string) {
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// Remove removes the installed templates matching id from the template cache.
+// When version is empty every installed version of the template is removed.
+// Returns the directories that were removed
+func Remove(templatePath string, id string, version string) ([]string, error) {
+	var matches []templateEntry
+	for _, entry := range discover(templatePath) {
+		if entry.Info.Template.Id != id {
+			continue
+		}
+		if version != "" && entry.Info.Template.Version != version {
+			continue
+		}
+		matches = append(matches, entry)
+	}
+
+	if len(matches) == 0 {
+		if version != "" {
+			return nil, fmt.Errorf("Couldn't find an installed template that matches '%s@%s'", id, version)
+		}
+		return nil, fmt.Errorf("Couldn't find an installed template that matches '%s'", id)
+	}
+
+	return removeEntries(templatePath, matches)
+}
+
+// Prune removes all but the newest version of each installed template from the
+// template cache. Returns the directories that were removed
+func Prune(templatePath string) ([]string, error) {
+	byId := make(map[string][]templateEntry)
+	for _, entry := range discover(templatePath) {
+		byId[entry.Info.Template.Id] = append(byId[entry.Info.Template.Id], entry)
+	}
+
+	var stale []templateEntry
+	for _, entries := range byId {
+		sortByVersion(entries)
+		stale = append(stale, entries[1:]...)
+	}
+
+	return removeEntries(templatePath, stale)
+}
+
+func removeEntries(templatePath string, entries []templateEntry) ([]string, error) {
+	var removed []string
+	for _, entry := range entries {
+		if !isWithinDirectory(templatePath, entry.Path) || filepath.Clean(templatePath) == filepath.Clean(entry.Path) {
+			return removed, fmt.Errorf("Refusing to remove '%s' as it is outside of '%s'", entry.Path, templatePath)
+		}
+
+		log.Debug().Msgf("Removing: %s", entry.Path)
+		if err := removeEntry(templatePath, entry); err != nil {
+			return removed, err
+		}
+		removed = append(removed, entry.Path)
+
+		// tidy up the id directory once its last version has gone
+		parent := filepath.Dir(entry.Path)
+		if filepath.Clean(filepath.Dir(parent)) == filepath.Clean(templatePath) {
+			if files, err := os.ReadDir(parent); err == nil && len(files) == 0 {
+				os.Remove(parent) //nolint:errcheck
+			}
+		}
+	}
+	return removed, nil
+}
+
+// removeEntry removes the directory of an installed template. A template
+// stored directly in <templatePath>/<id> can share its directory with the
+// versioned installs of the same id, in which case only the files of the
+// template itself are removed
+func removeEntry(templatePath string, entry templateEntry) error {
+	flat := filepath.Clean(filepath.Dir(entry.Path)) == filepath.Clean(templatePath)
+	nested, _ := filepath.Glob(filepath.Join(entry.Path, "*", TemplateConfigFileName))
+	if !flat || len(nested) == 0 {
+		return os.RemoveAll(entry.Path)
+	}
+
+	for _, name := range []string{TemplateConfigFileName, "content", PartialsDirName} {
+		if err := os.RemoveAll(filepath.Join(entry.Path, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortByVersion sorts template entries from the newest to the oldest version
+func sortByVersion(entries []templateEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return compareVersions(entries[i].Info.Template.Version, entries[j].Info.Template.Version) > 0
+	})
+}
+
+// compareVersions compares two template versions using semantic versioning.
+// Versions that are not valid semver sort before any valid version
+func compareVersions(a string, b string) int {
+	va, errA := semver.NewVersion(a)
+	vb, errB := semver.NewVersion(b)
+	switch {
+	case errA != nil && errB != nil:
+		return strings.Compare(a, b)
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+	return va.Compare(vb)
+}
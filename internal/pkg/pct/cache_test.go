@@ -0,0 +1,186 @@
+package pct
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// installTestTemplate writes a minimal template with the given id and version
+// into a versioned install directory within templatePath
+func installTestTemplate(t *testing.T, templatePath string, id string, version string) string {
+	dir := filepath.Join(templatePath, id, version)
+	if err := os.MkdirAll(filepath.Join(dir, "content"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	config := fmt.Sprintf("---\ntemplate:\n  id: %s\n  type: project\n  display: %s\n  version: %s\n", id, id, version)
+	if err := os.WriteFile(filepath.Join(dir, TemplateConfigFileName), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "content", "version.txt.tmpl"), []byte(version), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestParseTemplateReference(t *testing.T) {
+	tests := []struct {
+		ref         string
+		wantId      string
+		wantVersion string
+	}{
+		{ref: "full-project", wantId: "full-project", wantVersion: ""},
+		{ref: "full-project@0.2.0", wantId: "full-project", wantVersion: "0.2.0"},
+		{ref: "full-project@", wantId: "full-project", wantVersion: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			id, version := ParseTemplateReference(tt.ref)
+			assert.Equal(t, tt.wantId, id)
+			assert.Equal(t, tt.wantVersion, version)
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		name        string
+		id          string
+		version     string
+		wantRemoved []string
+		wantLeft    []string
+		wantErr     bool
+	}{
+		{
+			name:        "removes a single version of a template",
+			id:          "foo",
+			version:     "0.2.0",
+			wantRemoved: []string{filepath.Join("foo", "0.2.0")},
+			wantLeft:    []string{"bar@1.0.0", "foo@0.1.0"},
+		},
+		{
+			name:        "removes every version of a template",
+			id:          "foo",
+			wantRemoved: []string{filepath.Join("foo", "0.1.0"), filepath.Join("foo", "0.2.0")},
+			wantLeft:    []string{"bar@1.0.0"},
+		},
+		{
+			name:     "returns an error for a version that is not installed",
+			id:       "foo",
+			version:  "9.9.9",
+			wantLeft: []string{"bar@1.0.0", "foo@0.1.0", "foo@0.2.0"},
+			wantErr:  true,
+		},
+		{
+			name:     "returns an error for a template that is not installed",
+			id:       "wibble",
+			wantLeft: []string{"bar@1.0.0", "foo@0.1.0", "foo@0.2.0"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templatePath := t.TempDir()
+			installTestTemplate(t, templatePath, "foo", "0.1.0")
+			installTestTemplate(t, templatePath, "foo", "0.2.0")
+			installTestTemplate(t, templatePath, "bar", "1.0.0")
+
+			removed, err := Remove(templatePath, tt.id, tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Remove() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			var want []string
+			for _, r := range tt.wantRemoved {
+				want = append(want, filepath.Join(templatePath, r))
+			}
+			sort.Strings(removed)
+			assert.Equal(t, want, removed)
+			assert.Equal(t, tt.wantLeft, installedReferences(templatePath))
+		})
+	}
+
+	t.Run("removes the id directory with the last version", func(t *testing.T) {
+		templatePath := t.TempDir()
+		installTestTemplate(t, templatePath, "foo", "0.1.0")
+
+		_, err := Remove(templatePath, "foo", "0.1.0")
+		assert.NoError(t, err)
+		_, err = os.Stat(filepath.Join(templatePath, "foo"))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestPrune(t *testing.T) {
+	templatePath := t.TempDir()
+	installTestTemplate(t, templatePath, "foo", "0.2.0")
+	installTestTemplate(t, templatePath, "foo", "0.10.0")
+	installTestTemplate(t, templatePath, "foo", "0.9.1")
+	installTestTemplate(t, templatePath, "bar", "1.0.0")
+
+	removed, err := Prune(templatePath)
+	assert.NoError(t, err)
+
+	sort.Strings(removed)
+	assert.Equal(t, []string{
+		filepath.Join(templatePath, "foo", "0.2.0"),
+		filepath.Join(templatePath, "foo", "0.9.1"),
+	}, removed)
+	assert.Equal(t, []string{"bar@1.0.0", "foo@0.10.0"}, installedReferences(templatePath))
+}
+
+func TestPruneFlatTemplate(t *testing.T) {
+	// a template shipped directly in <templatePath>/<id>, with a newer version
+	// installed alongside it
+	templatePath := t.TempDir()
+	flat := installTestTemplate(t, t.TempDir(), "foo", "0.1.0")
+	if err := os.Rename(flat, filepath.Join(templatePath, "foo")); err != nil {
+		t.Fatal(err)
+	}
+	installTestTemplate(t, templatePath, "foo", "0.2.0")
+	assert.Equal(t, []string{"foo@0.1.0", "foo@0.2.0"}, installedReferences(templatePath))
+
+	removed, err := Prune(templatePath)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(templatePath, "foo")}, removed)
+	assert.Equal(t, []string{"foo@0.2.0"}, installedReferences(templatePath))
+	assert.FileExists(t, filepath.Join(templatePath, "foo", "0.2.0", "content", "version.txt.tmpl"))
+	_, err = os.Stat(filepath.Join(templatePath, "foo", "content"))
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = Remove(templatePath, "foo", "")
+	assert.NoError(t, err)
+	assert.Empty(t, installedReferences(templatePath))
+}
+
+func Test_compareVersions(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{a: "0.10.0", b: "0.2.0", want: 1},
+		{a: "0.1.0", b: "0.1.0", want: 0},
+		{a: "1.0.0-rc1", b: "1.0.0", want: -1},
+		{a: "not-a-version", b: "0.0.1", want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, compareVersions(tt.a, tt.b))
+		})
+	}
+}
+
+func installedReferences(templatePath string) []string {
+	var refs []string
+	for _, entry := range discover(templatePath) {
+		refs = append(refs, entry.Info.Template.Id+"@"+entry.Info.Template.Version)
+	}
+	sort.Strings(refs)
+	return refs
+}
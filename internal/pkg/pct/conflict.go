@@ -0,0 +1,91 @@
+package pct
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Policies for handling existing files that differ from the template output
+const (
+	ConflictFail      = "fail"
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictBackup    = "backup"
+)
+
+// ConflictPolicies lists the values accepted for DeployInfo.OnConflict
+var ConflictPolicies = []string{ConflictFail, ConflictSkip, ConflictOverwrite, ConflictBackup}
+
+// BackupSuffix is appended to the name of an existing file when it is moved
+// aside by the backup conflict policy. When that name is taken a number is
+// appended as well, e.g. .bak.1, so an earlier backup is never replaced
+const BackupSuffix = ".bak"
+
+// ConflictError lists every existing path that differs from the template
+// output, when the fail conflict policy is in use
+type ConflictError struct {
+	Paths []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("The following files already exist and differ from the template output:\n  - %s", strings.Join(e.Paths, "\n  - "))
+}
+
+// validateConflictPolicy returns an error for a policy that isn't one of
+// ConflictPolicies. An empty policy is the fail policy
+func validateConflictPolicy(policy string) error {
+	if policy == "" {
+		return nil
+	}
+	for _, p := range ConflictPolicies {
+		if p == policy {
+			return nil
+		}
+	}
+	return fmt.Errorf("Unknown conflict policy '%s', expected one of: %s", policy, strings.Join(ConflictPolicies, ", "))
+}
+
+// backupPath returns the first name for a backup of target that doesn't exist
+func backupPath(target string) string {
+	backup := target + BackupSuffix
+	for n := 1; ; n++ {
+		if _, err := os.Lstat(backup); os.IsNotExist(err) {
+			return backup
+		}
+		backup = fmt.Sprintf("%s%s.%d", target, BackupSuffix, n)
+	}
+}
+
+// applyConflictPolicy updates the action of each planned file that would
+// overwrite an existing file with different content, according to the policy.
+// A ConflictError listing every conflicting path is returned for the fail
+// policy
+func applyConflictPolicy(files []deployFile, policy string) error {
+	if policy == "" {
+		policy = ConflictFail
+	}
+
+	var conflicts []string
+	for i, f := range files {
+		if f.Action != ActionOverwrite {
+			continue
+		}
+
+		switch policy {
+		case ConflictFail:
+			files[i].Action = ActionConflict
+			conflicts = append(conflicts, f.TargetFilePath)
+		case ConflictSkip:
+			files[i].Action = ActionSkip
+		case ConflictBackup:
+			files[i].Action = ActionBackup
+			files[i].Backup = backupPath(f.TargetFilePath)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return &ConflictError{Paths: conflicts}
+	}
+	return nil
+}
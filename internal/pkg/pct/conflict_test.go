@@ -0,0 +1,184 @@
+package pct
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployConflicts(t *testing.T) {
+	tests := []struct {
+		name       string
+		onConflict string
+		wantAction string
+		wantErr    bool
+		wantFiles  map[string]string
+	}{
+		{
+			name:       "fails by default listing the conflicting files",
+			wantAction: ActionConflict,
+			wantErr:    true,
+			wantFiles: map[string]string{
+				"goodfile.txt": "edited",
+				"empty.txt":    "edited",
+			},
+		},
+		{
+			name:       "skips conflicting files",
+			onConflict: ConflictSkip,
+			wantAction: ActionSkip,
+			wantFiles: map[string]string{
+				"goodfile.txt": "edited",
+				"empty.txt":    "",
+			},
+		},
+		{
+			name:       "overwrites conflicting files",
+			onConflict: ConflictOverwrite,
+			wantAction: ActionOverwrite,
+			wantFiles: map[string]string{
+				"goodfile.txt": "This is wakka data",
+				"empty.txt":    "",
+			},
+		},
+		{
+			name:       "backs up conflicting files",
+			onConflict: ConflictBackup,
+			wantAction: ActionBackup,
+			wantFiles: map[string]string{
+				"goodfile.txt":     "This is wakka data",
+				"goodfile.txt.bak": "edited",
+				"empty.txt":        "",
+			},
+		},
+		{
+			name:       "rejects an unknown policy",
+			onConflict: "wibble",
+			wantErr:    true,
+			wantFiles: map[string]string{
+				"goodfile.txt": "edited",
+				"empty.txt":    "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			info := DeployInfo{
+				SelectedTemplate: "good-project",
+				TemplateCache:    "testdata/examples",
+				TargetOutputDir:  tmp,
+				TargetName:       "woo",
+				Overrides:        map[string]string{"example_data": "wakka"},
+			}
+			_, err := Deploy(info)
+			if err != nil {
+				t.Fatal(err)
+			}
+			target := filepath.Join(tmp, "woo", "goodfile.txt")
+			if err := os.WriteFile(target, []byte("edited"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr && tt.onConflict == "" {
+				// a second conflict shows every path is reported
+				if err := os.WriteFile(filepath.Join(tmp, "woo", "empty.txt"), []byte("edited"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			info.OnConflict = tt.onConflict
+			got, err := Deploy(info)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Deploy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if conflict, ok := err.(*ConflictError); ok {
+				assert.Equal(t, []string{filepath.Join(tmp, "woo", "empty.txt"), target}, conflict.Paths)
+			}
+			if !tt.wantErr {
+				assert.Equal(t, tt.wantAction, got.Files[2].Action)
+			}
+
+			for file, want := range tt.wantFiles {
+				content, err := os.ReadFile(filepath.Join(tmp, "woo", file))
+				assert.NoError(t, err)
+				assert.Equal(t, want, string(content), file)
+			}
+		})
+	}
+}
+
+func TestDeployUnknownConflictPolicy(t *testing.T) {
+	tmp := t.TempDir()
+	_, err := Deploy(DeployInfo{
+		SelectedTemplate: "good-project",
+		TemplateCache:    "testdata/examples",
+		TargetOutputDir:  tmp,
+		TargetName:       "woo",
+		OnConflict:       "bogus",
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Unknown conflict policy 'bogus'")
+	}
+
+	// nothing is deployed, even without any conflicts
+	_, err = os.Stat(filepath.Join(tmp, "woo"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDeployConflictsDryRun(t *testing.T) {
+	tmp := t.TempDir()
+	target := filepath.Join(tmp, "woo", "goodfile.txt")
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Deploy(DeployInfo{
+		SelectedTemplate: "good-project",
+		TemplateCache:    "testdata/examples",
+		TargetOutputDir:  tmp,
+		TargetName:       "woo",
+		DryRun:           true,
+		OnConflict:       ConflictBackup,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, DeployedFile{Path: target, Action: ActionBackup, Backup: target + BackupSuffix}, got.Files[2])
+	_, err = os.Stat(target + BackupSuffix)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDeployConflictsKeepsEarlierBackups(t *testing.T) {
+	tmp := t.TempDir()
+	target := filepath.Join(tmp, "woo", "goodfile.txt")
+	info := DeployInfo{
+		SelectedTemplate: "good-project",
+		TemplateCache:    "testdata/examples",
+		TargetOutputDir:  tmp,
+		TargetName:       "woo",
+		OnConflict:       ConflictBackup,
+	}
+
+	var backups []string
+	for _, edit := range []string{"first edit", "second edit"} {
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(target, []byte(edit), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := Deploy(info)
+		assert.NoError(t, err)
+		backups = append(backups, got.Files[2].Backup)
+	}
+
+	assert.Equal(t, []string{target + BackupSuffix, target + BackupSuffix + ".1"}, backups)
+	for i, want := range []string{"first edit", "second edit"} {
+		content, err := os.ReadFile(backups[i])
+		assert.NoError(t, err)
+		assert.Equal(t, want, string(content))
+	}
+}
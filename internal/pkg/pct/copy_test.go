@@ -0,0 +1,63 @@
+package pct
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployCopiesFiles(t *testing.T) {
+	cache := t.TempDir()
+	dir := installTestTemplate(t, cache, "copy", "0.1.0")
+	config := "---\ntemplate:\n  id: copy\n  type: project\n  version: 0.1.0\n  copy_only:\n    - \"*.erb\"\n"
+	binary := []byte{0x89, 'P', 'N', 'G', 0x00, '{', '{', 0xff, '}', '}', 0x0a}
+	files := map[string][]byte{
+		TemplateConfigFileName:                        []byte(config),
+		filepath.Join("content", "logo.png"):          binary,
+		filepath.Join("content", "motd.erb.tmpl"):     []byte("<%= @motd %> {{ not rendered }}"),
+		filepath.Join("content", "rendered.txt.tmpl"): []byte("{{ .pct_name }}"),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tmp := t.TempDir()
+	info := DeployInfo{
+		SelectedTemplate: "copy",
+		TemplateCache:    cache,
+		TargetOutputDir:  tmp,
+		TargetName:       "woo",
+	}
+
+	var out bytes.Buffer
+	_, err := Diff(info, &out)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "Binary file "+filepath.Join(tmp, "woo", "logo.png")+" differs")
+
+	got, err := Deploy(info)
+	assert.NoError(t, err)
+
+	copied := make(map[string]bool)
+	for _, f := range got.Files {
+		copied[filepath.Base(f.Path)] = f.Copied
+	}
+	assert.Equal(t, map[string]bool{
+		"woo":          false,
+		"logo.png":     true,
+		"motd.erb":     true,
+		"rendered.txt": false,
+		"version.txt":  false,
+	}, copied)
+
+	content, _ := os.ReadFile(filepath.Join(tmp, "woo", "logo.png"))
+	assert.Equal(t, binary, content)
+	content, _ = os.ReadFile(filepath.Join(tmp, "woo", "motd.erb"))
+	assert.Equal(t, "<%= @motd %> {{ not rendered }}", string(content))
+	content, _ = os.ReadFile(filepath.Join(tmp, "woo", "rendered.txt"))
+	assert.Equal(t, "woo", string(content))
+}
@@ -0,0 +1,79 @@
+package pct
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Diff renders the selected template as Deploy would and writes a unified diff
+// of the output against the files currently on disk to w. Files that do not
+// exist yet are shown in full under a "new file" header, and binary files are
+// only reported as differing. Nothing is written to the target directory. The
+// returned bool reports whether any differences were found
+func Diff(info DeployInfo, w io.Writer) (bool, error) {
+	// every difference is shown, regardless of how a deploy would resolve it
+	info.OnConflict = ConflictOverwrite
+	plan, err := planDeployment(info)
+	if err != nil {
+		return false, err
+	}
+
+	changed := false
+	for _, f := range plan.Files {
+		if f.IsDirectory || f.Action == ActionUnchanged {
+			continue
+		}
+		changed = true
+
+		if f.IsCopy && !utf8.ValidString(f.Content) {
+			if f.Action == ActionCreate {
+				fmt.Fprintf(w, "new file: %s\n", f.TargetFilePath)
+			}
+			fmt.Fprintf(w, "Binary file %s differs\n", f.TargetFilePath)
+			continue
+		}
+
+		diff := difflib.UnifiedDiff{
+			B:        diffLines(f.Content),
+			FromFile: f.TargetFilePath,
+			ToFile:   f.TargetFilePath,
+			Context:  3,
+		}
+		if f.Action == ActionCreate {
+			fmt.Fprintf(w, "new file: %s\n", f.TargetFilePath)
+			diff.FromFile = "/dev/null"
+		} else {
+			if fi, err := os.Stat(f.TargetFilePath); err == nil && executableChanged(fi.Mode(), f.Mode) {
+				fmt.Fprintf(w, "mode change %04o => %04o %s\n", fi.Mode().Perm(), f.Mode, f.TargetFilePath)
+			}
+			existing, err := os.ReadFile(f.TargetFilePath)
+			if err != nil {
+				return changed, err
+			}
+			diff.A = diffLines(string(existing))
+		}
+
+		err := difflib.WriteUnifiedDiff(w, diff)
+		if err != nil {
+			return changed, err
+		}
+	}
+
+	return changed, nil
+}
+
+// diffLines splits content into the lines of a unified diff. A last line
+// without a line ending is followed by a "\\ No newline at end of file"
+// marker, as diff does, so it differs from the same line with one
+func diffLines(s string) []string {
+	lines := splitLines(s)
+	if n := len(lines); n > 0 && !strings.HasSuffix(lines[n-1], "\n") {
+		lines[n-1] += "\n\\ No newline at end of file\n"
+	}
+	return lines
+}
@@ -0,0 +1,76 @@
+package pct
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	tmp := t.TempDir()
+	info := DeployInfo{
+		SelectedTemplate: "good-project",
+		TemplateCache:    "testdata/examples",
+		TargetOutputDir:  tmp,
+		TargetName:       "woo",
+		Overrides:        map[string]string{"example_data": "wakka"},
+	}
+	target := filepath.Join(tmp, "woo", "goodfile.txt")
+
+	var out bytes.Buffer
+	changed, err := Diff(info, &out)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Contains(t, out.String(), "new file: "+target+"\n--- /dev/null\n+++ "+target+"\n")
+	assert.Contains(t, out.String(), "+This is wakka data")
+	_, err = os.Stat(filepath.Join(tmp, "woo"))
+	assert.True(t, os.IsNotExist(err), "a diff should not write anything")
+
+	_, err = Deploy(info)
+	assert.NoError(t, err)
+
+	out.Reset()
+	changed, err = Diff(info, &out)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Empty(t, out.String())
+
+	err = os.WriteFile(target, []byte("This is edited data"), 0644)
+	assert.NoError(t, err)
+
+	out.Reset()
+	changed, err = Diff(info, &out)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "--- "+target+"\n+++ "+target+"\n@@ -1 +1 @@\n-This is edited data\n\\ No newline at end of file\n+This is wakka data\n\\ No newline at end of file\n", out.String())
+}
+
+func TestDiffLineEndings(t *testing.T) {
+	cache := t.TempDir()
+	installLayeredTemplate(t, cache, "endings", "0.1.0", "", "", map[string]string{
+		"missing.txt.tmpl": "a\nc",
+		"added.txt.tmpl":   "a\nc\n",
+	})
+	tmp := t.TempDir()
+	output := filepath.Join(tmp, "woo")
+	if err := os.MkdirAll(output, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"missing.txt": "a\nb\n", "added.txt": "a\nb"} {
+		if err := os.WriteFile(filepath.Join(output, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	_, err := Diff(DeployInfo{SelectedTemplate: "endings", TemplateCache: cache, TargetOutputDir: tmp, TargetName: "woo"}, &out)
+	assert.NoError(t, err)
+
+	missing := filepath.Join(output, "missing.txt")
+	added := filepath.Join(output, "added.txt")
+	assert.Contains(t, out.String(), "--- "+missing+"\n+++ "+missing+"\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n\\ No newline at end of file\n")
+	assert.Contains(t, out.String(), "--- "+added+"\n+++ "+added+"\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n")
+}
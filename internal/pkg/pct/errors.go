@@ -0,0 +1,65 @@
+package pct
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FileError describes a template file that could not be deployed. Line and
+// Column give the position in the template reported by text/template, and are
+// zero when unknown
+type FileError struct {
+	TemplatePath   string `json:"template"`
+	TargetFilePath string `json:"target,omitempty"`
+	Line           int    `json:"line,omitempty"`
+	Column         int    `json:"column,omitempty"`
+	Message        string `json:"message"`
+}
+
+func (e FileError) Error() string {
+	location := e.TemplatePath
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, e.Line)
+	}
+	if e.Column > 0 {
+		location = fmt.Sprintf("%s:%d", location, e.Column)
+	}
+	return fmt.Sprintf("%s: %s", location, e.Message)
+}
+
+// DeployError lists every template file that failed to deploy
+type DeployError struct {
+	Files []FileError
+}
+
+func (e *DeployError) Error() string {
+	var problems []string
+	for _, f := range e.Files {
+		problems = append(problems, f.Error())
+	}
+	return fmt.Sprintf("Failed to render %d template file(s):\n  - %s", len(e.Files), strings.Join(problems, "\n  - "))
+}
+
+// templateErrorPattern matches the position text/template reports in parse
+// and execution errors, e.g. `template: name.tmpl:3:7: executing ...`
+var templateErrorPattern = regexp.MustCompile(`^template: [^:]+:(\d+)(?::(\d+))?: (.*)$`)
+
+// newFileError builds a FileError for a template file, extracting the line
+// and column from errors returned by text/template
+func newFileError(templateFile PuppetContentTemplateFileInfo, err error) FileError {
+	fe := FileError{
+		TemplatePath:   templateFile.TemplatePath,
+		TargetFilePath: templateFile.TargetFilePath,
+		Message:        err.Error(),
+	}
+
+	m := templateErrorPattern.FindStringSubmatch(strings.SplitN(err.Error(), "\n", 2)[0])
+	if m != nil {
+		fe.Line, _ = strconv.Atoi(m[1])
+		fe.Column, _ = strconv.Atoi(m[2])
+		fe.Message = m[3]
+	}
+	return fe
+}
@@ -0,0 +1,76 @@
+package pct
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployErrors(t *testing.T) {
+	cache := t.TempDir()
+	dir := installTestTemplate(t, cache, "broken", "0.1.0")
+	templates := map[string]string{
+		"parse.txt.tmpl":   "line one\n{{ .pct_name ",
+		"execute.txt.tmpl": "line one\nline two {{ index .pct_name 5 }}\n",
+	}
+	for name, content := range templates {
+		if err := os.WriteFile(filepath.Join(dir, "content", name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tmp := t.TempDir()
+	_, err := Deploy(DeployInfo{
+		SelectedTemplate: "broken",
+		TemplateCache:    cache,
+		TargetOutputDir:  tmp,
+		TargetName:       "woo",
+	})
+
+	if assert.IsType(t, &DeployError{}, err) {
+		files := err.(*DeployError).Files
+		if assert.Len(t, files, 2) {
+			assert.Equal(t, filepath.Join(dir, "content", "execute.txt.tmpl"), files[0].TemplatePath)
+			assert.Equal(t, filepath.Join(tmp, "woo", "execute.txt"), files[0].TargetFilePath)
+			assert.Equal(t, 2, files[0].Line)
+			assert.Equal(t, 12, files[0].Column)
+			assert.Contains(t, files[0].Message, "index out of range")
+
+			assert.Equal(t, filepath.Join(dir, "content", "parse.txt.tmpl"), files[1].TemplatePath)
+			assert.Equal(t, 2, files[1].Line)
+			assert.Equal(t, 0, files[1].Column)
+		}
+	}
+	assertDirEntries(t, tmp, nil)
+}
+
+func TestFileError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		err  FileError
+		want string
+	}{
+		{
+			name: "with a line and column",
+			err:  FileError{TemplatePath: "a.tmpl", Line: 3, Column: 7, Message: "bad"},
+			want: "a.tmpl:3:7: bad",
+		},
+		{
+			name: "with a line",
+			err:  FileError{TemplatePath: "a.tmpl", Line: 3, Message: "bad"},
+			want: "a.tmpl:3: bad",
+		},
+		{
+			name: "without a position",
+			err:  FileError{TemplatePath: "a.tmpl", Message: "bad"},
+			want: "a.tmpl: bad",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.err.Error())
+		})
+	}
+}
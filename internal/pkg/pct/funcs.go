@@ -0,0 +1,343 @@
+package pct
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/Masterminds/semver/v3"
+	"gopkg.in/yaml.v2"
+)
+
+// templateFuncs returns the functions available to every rendered template
+// file. As with sprig, the value a function operates on is its last argument,
+// so functions read naturally in a pipeline, e.g. `{{ .name | replace "-" "_" }}`
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// strings
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"title":      strings.Title,
+		"snake":      func(s string) string { return strings.ToLower(strings.Join(splitWords(s), "_")) },
+		"kebab":      func(s string) string { return strings.ToLower(strings.Join(splitWords(s), "-")) },
+		"camel":      camelCase,
+		"pascal":     pascalCase,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix string, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix string, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old string, new string, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":   func(substr string, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix string, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix string, s string) bool { return strings.HasSuffix(s, suffix) },
+		"repeat":     func(count int, s string) string { return strings.Repeat(s, count) },
+		"indent":     indent,
+		"nindent":    func(spaces int, s string) string { return "\n" + indent(spaces, s) },
+		"quote":      func(v interface{}) string { return fmt.Sprintf("%q", fmt.Sprint(v)) },
+		"squote":     func(v interface{}) string { return "'" + fmt.Sprint(v) + "'" },
+		"split":      func(sep string, s string) []string { return strings.Split(s, sep) },
+		"join":       join,
+
+		// lists and dicts
+		"list":      func(items ...interface{}) []interface{} { return items },
+		"first":     first,
+		"last":      last,
+		"append":    func(list interface{}, v interface{}) ([]interface{}, error) { return appendList(list, v) },
+		"has":       has,
+		"uniq":      uniq,
+		"sortAlpha": sortAlpha,
+		"dict":      dict,
+		"get":       func(d map[string]interface{}, key string) interface{} { return d[key] },
+		"hasKey":    func(d map[string]interface{}, key string) bool { _, ok := d[key]; return ok },
+		"keys":      keys,
+
+		// defaults
+		"default":  func(def interface{}, given interface{}) interface{} { return coalesce(given, def) },
+		"coalesce": coalesce,
+		"empty":    isEmpty,
+		"ternary": func(t interface{}, f interface{}, condition bool) interface{} {
+			if condition {
+				return t
+			}
+			return f
+		},
+
+		// dates and identifiers
+		"now":    time.Now,
+		"date":   func(layout string, t time.Time) string { return t.Format(layout) },
+		"uuidv4": uuidv4,
+
+		// encoding
+		"toYaml":   toYaml,
+		"toJson":   toJSON,
+		"fromJson": fromJSON,
+
+		// versions
+		"semverCompare": semverCompare,
+
+		// puppet
+		"toClassName": func(itemName string) string {
+			return strings.Title(strings.ToLower(itemName))
+		},
+		"validModuleName": func(name string) bool { return moduleNamePattern.MatchString(name) },
+		"moduleAuthor":    func(fullName string) string { return splitModuleName(fullName)[0] },
+		"moduleName":      func(fullName string) string { return splitModuleName(fullName)[1] },
+	}
+}
+
+// splitWords breaks a string into words at any character that isn't a letter
+// or digit, and at changes from lower to upper case
+func splitWords(s string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+			continue
+		}
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				words = append(words, string(word))
+				word = nil
+			}
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+func pascalCase(s string) string {
+	var b strings.Builder
+	for _, w := range splitWords(s) {
+		b.WriteString(strings.Title(strings.ToLower(w)))
+	}
+	return b.String()
+}
+
+func camelCase(s string) string {
+	p := []rune(pascalCase(s))
+	if len(p) > 0 {
+		p[0] = unicode.ToLower(p[0])
+	}
+	return string(p)
+}
+
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+// asList converts any slice or array to a []interface{}
+func asList(list interface{}) ([]interface{}, error) {
+	if list == nil {
+		return nil, nil
+	}
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("'%v' is not a list", list)
+	}
+	items := make([]interface{}, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items, nil
+}
+
+func join(sep string, list interface{}) (string, error) {
+	items, err := asList(list)
+	if err != nil {
+		return "", err
+	}
+	s := make([]string, len(items))
+	for i, item := range items {
+		s[i] = fmt.Sprint(item)
+	}
+	return strings.Join(s, sep), nil
+}
+
+func first(list interface{}) (interface{}, error) {
+	items, err := asList(list)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[0], nil
+}
+
+func last(list interface{}) (interface{}, error) {
+	items, err := asList(list)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[len(items)-1], nil
+}
+
+func appendList(list interface{}, v interface{}) ([]interface{}, error) {
+	items, err := asList(list)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]interface{}{}, items...), v), nil
+}
+
+func has(needle interface{}, list interface{}) (bool, error) {
+	items, err := asList(list)
+	if err != nil {
+		return false, err
+	}
+	for _, item := range items {
+		if reflect.DeepEqual(item, needle) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func uniq(list interface{}) ([]interface{}, error) {
+	items, err := asList(list)
+	if err != nil {
+		return nil, err
+	}
+	var unique []interface{}
+	for _, item := range items {
+		if found, _ := has(item, unique); !found {
+			unique = append(unique, item)
+		}
+	}
+	return unique, nil
+}
+
+func sortAlpha(list interface{}) ([]string, error) {
+	items, err := asList(list)
+	if err != nil {
+		return nil, err
+	}
+	s := make([]string, len(items))
+	for i, item := range items {
+		s[i] = fmt.Sprint(item)
+	}
+	sort.Strings(s)
+	return s, nil
+}
+
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict expects key and value pairs, got %d arguments", len(pairs))
+	}
+	d := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		d[fmt.Sprint(pairs[i])] = pairs[i+1]
+	}
+	return d, nil
+}
+
+func keys(d map[string]interface{}) []string {
+	k := make([]string, 0, len(d))
+	for key := range d {
+		k = append(k, key)
+	}
+	sort.Strings(k)
+	return k
+}
+
+// isEmpty reports whether a value is nil or the zero value of its type,
+// including empty strings, lists and maps
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.String:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	}
+	return rv.IsZero()
+}
+
+func coalesce(values ...interface{}) interface{} {
+	for _, v := range values {
+		if !isEmpty(v) {
+			return v
+		}
+	}
+	return nil
+}
+
+func uuidv4() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+func toYaml(v interface{}) (string, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+func toJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func fromJSON(s string) (interface{}, error) {
+	var v interface{}
+	err := json.Unmarshal([]byte(s), &v)
+	return v, err
+}
+
+// semverCompare reports whether a version satisfies a constraint, e.g.
+// `semverCompare ">= 1.2.0" .puppet_module.version`
+func semverCompare(constraint string, version string) (bool, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false, err
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false, err
+	}
+	return c.Check(v), nil
+}
+
+// moduleNamePattern matches a valid Puppet module name, without the author
+var moduleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// splitModuleName splits a full module name such as `puppetlabs-stdlib` or
+// `puppetlabs/stdlib` into its author and name. A name without an author
+// returns an empty author
+func splitModuleName(fullName string) [2]string {
+	parts := strings.SplitN(fullName, "-", 2)
+	if len(parts) != 2 {
+		parts = strings.SplitN(fullName, "/", 2)
+	}
+	if len(parts) != 2 {
+		return [2]string{"", fullName}
+	}
+	return [2]string{parts[0], parts[1]}
+}
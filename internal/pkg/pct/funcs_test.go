@@ -0,0 +1,84 @@
+package pct
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_templateFuncs(t *testing.T) {
+	vars := map[string]interface{}{
+		"name":    "my-Module_nameHTTPServer",
+		"module":  "puppetlabs-stdlib",
+		"list":    []interface{}{"b", "a", "b"},
+		"dict":    map[string]interface{}{"b": 2, "a": 1},
+		"version": "1.2.3",
+		"empty":   "",
+		"json":    `{"a": [1, 2]}`,
+	}
+
+	tests := []struct {
+		tmpl    string
+		want    string
+		wantErr bool
+	}{
+		{tmpl: `{{ .name | snake }}`, want: "my_module_name_http_server"},
+		{tmpl: `{{ .name | kebab }}`, want: "my-module-name-http-server"},
+		{tmpl: `{{ .name | camel }}`, want: "myModuleNameHttpServer"},
+		{tmpl: `{{ .name | pascal }}`, want: "MyModuleNameHttpServer"},
+		{tmpl: `{{ "Foo" | lower }} {{ "foo" | upper }} {{ "foo bar" | title }}`, want: "foo FOO Foo Bar"},
+		{tmpl: `{{ "  foo  " | trim }}|{{ "foo.pp" | trimSuffix ".pp" }}|{{ "v1" | trimPrefix "v" }}`, want: "foo|foo|1"},
+		{tmpl: `{{ .module | replace "-" "_" }}`, want: "puppetlabs_stdlib"},
+		{tmpl: `{{ .module | contains "stdlib" }} {{ .module | hasPrefix "puppet" }} {{ .module | hasSuffix "x" }}`, want: "true true false"},
+		{tmpl: `{{ "ab" | repeat 2 }}`, want: "abab"},
+		{tmpl: `{{ "a\nb" | indent 2 }}`, want: "  a\n  b"},
+		{tmpl: `x:{{ "a" | nindent 2 }}`, want: "x:\n  a"},
+		{tmpl: `{{ .version | quote }} {{ .version | squote }}`, want: `"1.2.3" '1.2.3'`},
+		{tmpl: `{{ .module | split "-" | join "," }}`, want: "puppetlabs,stdlib"},
+		{tmpl: `{{ list 1 2 3 | join "" }}`, want: "123"},
+		{tmpl: `{{ first .list }} {{ last .list }} {{ append .list "c" | join "" }}`, want: "b b babc"},
+		{tmpl: `{{ has "a" .list }} {{ has "z" .list }}`, want: "true false"},
+		{tmpl: `{{ uniq .list | join "" }} {{ sortAlpha .list | join "" }}`, want: "ba abb"},
+		{tmpl: `{{ $d := dict "x" 1 "y" 2 }}{{ get $d "y" }} {{ hasKey $d "z" }} {{ keys .dict | join "" }}`, want: "2 false ab"},
+		{tmpl: `{{ dict "x" }}`, wantErr: true},
+		{tmpl: `{{ .empty | default "fallback" }} {{ .version | default "fallback" }}`, want: "fallback 1.2.3"},
+		{tmpl: `{{ coalesce .empty .missing "c" }} {{ empty .empty }} {{ empty .list }}`, want: "c true false"},
+		{tmpl: `{{ ternary "yes" "no" true }} {{ ternary "yes" "no" false }}`, want: "yes no"},
+		{tmpl: `{{ now | date "2006" | len }}`, want: "4"},
+		{tmpl: `{{ .dict | toYaml }}`, want: "a: 1\nb: 2"},
+		{tmpl: `{{ .dict | toJson }}`, want: `{"a":1,"b":2}`},
+		{tmpl: `{{ (.json | fromJson).a | join "," }}`, want: "1,2"},
+		{tmpl: `{{ "{" | fromJson }}`, wantErr: true},
+		{tmpl: `{{ semverCompare ">= 1.2.0" .version }} {{ semverCompare "^2" .version }}`, want: "true false"},
+		{tmpl: `{{ semverCompare ">= 1.2.0" "wibble" }}`, wantErr: true},
+		{tmpl: `{{ "MYCLASS" | toClassName }}`, want: "Myclass"},
+		{tmpl: `{{ validModuleName "stdlib" }} {{ validModuleName "Std-lib" }}`, want: "true false"},
+		{tmpl: `{{ moduleAuthor .module }} {{ moduleName .module }} {{ moduleName "puppetlabs/apache" }}`, want: "puppetlabs stdlib apache"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			tmpl, err := template.New("test").Funcs(templateFuncs()).Parse(tt.tmpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got bytes.Buffer
+			err = tmpl.Execute(&got, vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got.String())
+			}
+		})
+	}
+
+	t.Run("uuidv4", func(t *testing.T) {
+		id, err := uuidv4()
+		assert.NoError(t, err)
+		assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), id)
+	})
+}
@@ -0,0 +1,197 @@
+package pct
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/rs/zerolog/log"
+)
+
+// PuppetContentTemplateHook is a command a template runs after it has been
+// deployed. Dir is relative to the output directory, and the hook only runs
+// when the optional When expression is true. The command, its arguments and
+// its directory are rendered with the template data. For example:
+//
+//	hooks:
+//	  - command: git
+//	    args: [init]
+//	    when: .git.init
+type PuppetContentTemplateHook struct {
+	Command string   `mapstructure:"command" json:"command"`
+	Args    []string `mapstructure:"args" json:"args,omitempty"`
+	Dir     string   `mapstructure:"dir" json:"dir,omitempty"`
+	When    string   `mapstructure:"when" json:"when,omitempty"`
+}
+
+// deployHook is a hook resolved against the template data, ready to run
+type deployHook struct {
+	Template string
+	Command  string
+	Args     []string
+	Dir      string
+}
+
+func (h deployHook) String() string {
+	return strings.Join(append([]string{h.Command}, h.Args...), " ")
+}
+
+// planHooks resolves the hooks of every template of a deployment, in the order
+// the templates are deployed, skipping those whose condition is false
+func planHooks(layers []templateEntry, outputDir string, config map[string]interface{}) ([]deployHook, error) {
+	var hooks []deployHook
+	for _, layer := range layers {
+		id := layer.Info.Template.Id
+		for _, hook := range layer.Info.Template.Hooks {
+			if hook.Command == "" {
+				return nil, fmt.Errorf("Invalid hook in '%s': a command is required", id)
+			}
+
+			if hook.When != "" {
+				run, err := evaluateCondition(hook.When, config)
+				if err != nil {
+					return nil, fmt.Errorf("Invalid hook '%s' in '%s': %s", hook.Command, id, err)
+				}
+				if !run {
+					log.Debug().Msgf("Skipping hook '%s' of '%s'", hook.Command, id)
+					continue
+				}
+			}
+
+			h, err := resolveHook(id, hook, outputDir, config)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid hook '%s' in '%s': %s", hook.Command, id, err)
+			}
+			hooks = append(hooks, h)
+		}
+	}
+	return hooks, nil
+}
+
+func resolveHook(id string, hook PuppetContentTemplateHook, outputDir string, config map[string]interface{}) (deployHook, error) {
+	render := func(text string) (string, error) {
+		tmpl, err := template.New(id).Funcs(templateFuncs()).Parse(text)
+		if err != nil {
+			return "", err
+		}
+		var out bytes.Buffer
+		err = tmpl.Execute(&out, config)
+		return out.String(), err
+	}
+
+	h := deployHook{Template: id}
+	var err error
+	if h.Command, err = render(hook.Command); err != nil {
+		return deployHook{}, err
+	}
+	for _, arg := range hook.Args {
+		rendered, err := render(arg)
+		if err != nil {
+			return deployHook{}, err
+		}
+		h.Args = append(h.Args, rendered)
+	}
+
+	dir, err := render(hook.Dir)
+	if err != nil {
+		return deployHook{}, err
+	}
+	h.Dir = filepath.Join(outputDir, dir)
+	if filepath.IsAbs(dir) || !isWithinDirectory(outputDir, h.Dir) {
+		return deployHook{}, fmt.Errorf("the directory '%s' is outside the output directory", dir)
+	}
+	return h, nil
+}
+
+// hooksTrusted reports whether the hooks of a deployment can run without
+// confirmation, either because every hook is trusted or because each
+// template declaring a hook is listed in TrustedTemplates
+func hooksTrusted(info DeployInfo, hooks []deployHook) bool {
+	if info.TrustHooks {
+		return true
+	}
+	for _, h := range hooks {
+		trusted := false
+		for _, id := range info.TrustedTemplates {
+			if id == h.Template {
+				trusted = true
+				break
+			}
+		}
+		if !trusted {
+			return false
+		}
+	}
+	return true
+}
+
+// confirmHooks lists the hooks a deployment will run and asks whether to run
+// them, defaulting to no
+func confirmHooks(hooks []deployHook, in io.Reader, out io.Writer) (bool, error) {
+	if in == nil {
+		return false, nil
+	}
+
+	fmt.Fprintln(out, "The template runs the following commands:")
+	for _, h := range hooks {
+		fmt.Fprintf(out, "  %s (in %s)\n", h, h.Dir)
+	}
+	fmt.Fprint(out, "Run them? [y/N]: ")
+
+	line, err := bufferedReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes", nil
+}
+
+// runHooks runs each hook in order, stopping at the first that fails
+func runHooks(hooks []deployHook, out io.Writer) ([]string, error) {
+	var ran []string
+	for _, h := range hooks {
+		fmt.Fprintf(out, "Running: %s\n", h)
+		cmd := exec.Command(h.Command, h.Args...)
+		cmd.Dir = h.Dir
+		cmd.Stdout = out
+		cmd.Stderr = out
+		if err := cmd.Run(); err != nil {
+			return ran, fmt.Errorf("Hook '%s' of '%s' failed: %s", h, h.Template, err)
+		}
+		ran = append(ran, h.String())
+	}
+	return ran, nil
+}
+
+// deployHooks runs the hooks of a deployment unless they are disabled,
+// asking for confirmation when the template isn't trusted
+func deployHooks(info DeployInfo, hooks []deployHook) ([]string, error) {
+	if len(hooks) == 0 {
+		return nil, nil
+	}
+	if info.NoHooks {
+		log.Info().Msgf("Skipped %d hook(s)", len(hooks))
+		return nil, nil
+	}
+
+	out := info.Out
+	if out == nil {
+		out = os.Stderr
+	}
+	if !hooksTrusted(info, hooks) {
+		run, err := confirmHooks(hooks, info.In, out)
+		if err != nil {
+			return nil, err
+		}
+		if !run {
+			log.Warn().Msgf("Skipped %d hook(s) of an untrusted template", len(hooks))
+			return nil, nil
+		}
+	}
+	return runHooks(hooks, out)
+}
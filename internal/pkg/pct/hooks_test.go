@@ -0,0 +1,197 @@
+package pct
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test hooks use sh")
+	}
+
+	cache := t.TempDir()
+	installLayeredTemplate(t, cache, "hooks", "0.1.0", `  hooks:
+    - command: sh
+      args: ["-c", "echo {{ .pct_name }} >> hooks.log"]
+    - command: sh
+      args: ["-c", "pwd >> ../hooks.log"]
+      dir: sub
+    - command: sh
+      args: ["-c", "echo skipped >> hooks.log"]
+      when: .run_skipped
+`, "run_skipped: false\n", nil)
+	if err := os.Mkdir(filepath.Join(cache, "hooks", "0.1.0", "content", "sub"), 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		info    DeployInfo
+		in      string
+		want    string
+		wantRan int
+	}{
+		{
+			name:    "runs trusted hooks",
+			info:    DeployInfo{TrustHooks: true},
+			want:    "woo\n{{dir}}/sub\n",
+			wantRan: 2,
+		},
+		{
+			name:    "runs hooks of trusted templates",
+			info:    DeployInfo{TrustedTemplates: []string{"hooks"}},
+			want:    "woo\n{{dir}}/sub\n",
+			wantRan: 2,
+		},
+		{
+			name:    "runs confirmed hooks",
+			in:      "y\n",
+			want:    "woo\n{{dir}}/sub\n",
+			wantRan: 2,
+		},
+		{
+			name: "skips unconfirmed hooks",
+			in:   "\n",
+		},
+		{
+			name: "skips hooks without confirmation",
+		},
+		{
+			name: "skips disabled hooks",
+			info: DeployInfo{NoHooks: true, TrustHooks: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			var out bytes.Buffer
+			info := tt.info
+			info.SelectedTemplate = "hooks"
+			info.TemplateCache = cache
+			info.TargetOutputDir = tmp
+			info.TargetName = "woo"
+			info.Out = &out
+			if tt.in != "" {
+				info.In = strings.NewReader(tt.in)
+			}
+
+			got, err := Deploy(info)
+			assert.NoError(t, err)
+			assert.Len(t, got.Hooks, tt.wantRan)
+
+			log, _ := os.ReadFile(filepath.Join(tmp, "woo", "hooks.log"))
+			dir, _ := filepath.EvalSymlinks(filepath.Join(tmp, "woo"))
+			assert.Equal(t, strings.ReplaceAll(tt.want, "{{dir}}", dir), string(log))
+			if tt.in != "" {
+				assert.Contains(t, out.String(), "Run them? [y/N]")
+			}
+			// the prompt and progress of hooks go to Out rather than stdout
+			assert.Equal(t, tt.wantRan, strings.Count(out.String(), "Running: sh -c"))
+		})
+	}
+}
+
+func TestDeployHooksAfterPrompts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test hooks use sh")
+	}
+
+	cache := t.TempDir()
+	installLayeredTemplate(t, cache, "hooks", "0.1.0", `  hooks:
+    - command: sh
+      args: ["-c", "echo {{ .greeting }} >> hooks.log"]
+`, "greeting: hi\n", nil)
+
+	// answers and the confirmation arrive together, as when piped to pct new
+	tmp := t.TempDir()
+	var out bytes.Buffer
+	in := bufio.NewReader(strings.NewReader("hello\ny\n"))
+	info := DeployInfo{SelectedTemplate: "hooks", TemplateCache: cache, TargetOutputDir: tmp, TargetName: "woo", In: in, Out: &out}
+
+	answers, err := PromptParameters(info, in, &out)
+	assert.NoError(t, err)
+	info.Answers = answers
+
+	got, err := Deploy(info)
+	assert.NoError(t, err)
+	assert.Len(t, got.Hooks, 1)
+	log, _ := os.ReadFile(filepath.Join(tmp, "woo", "hooks.log"))
+	assert.Equal(t, "hello\n", string(log))
+}
+
+func TestDeployHooksFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test hooks use sh")
+	}
+
+	cache := t.TempDir()
+	installLayeredTemplate(t, cache, "hooks", "0.1.0", `  hooks:
+    - command: sh
+      args: ["-c", "exit 3"]
+    - command: sh
+      args: ["-c", "touch never"]
+`, "", nil)
+
+	tmp := t.TempDir()
+	got, err := Deploy(DeployInfo{
+		SelectedTemplate: "hooks",
+		TemplateCache:    cache,
+		TargetOutputDir:  tmp,
+		TargetName:       "woo",
+		TrustHooks:       true,
+		Out:              &bytes.Buffer{},
+	})
+	if assert.Error(t, err) {
+		assert.Equal(t, "Hook 'sh -c exit 3' of 'hooks' failed: exit status 3", err.Error())
+	}
+
+	// the deployed files are kept
+	assert.NotEmpty(t, got.Files)
+	assert.FileExists(t, filepath.Join(tmp, "woo", "version.txt"))
+	_, err = os.Stat(filepath.Join(tmp, "woo", "never"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func Test_planHooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    PuppetContentTemplateHook
+		wantErr string
+	}{
+		{
+			name:    "requires a command",
+			hook:    PuppetContentTemplateHook{Args: []string{"init"}},
+			wantErr: "Invalid hook in 'hooks': a command is required",
+		},
+		{
+			name:    "rejects a directory outside the output directory",
+			hook:    PuppetContentTemplateHook{Command: "git", Dir: "../elsewhere"},
+			wantErr: "Invalid hook 'git' in 'hooks': the directory '../elsewhere' is outside the output directory",
+		},
+		{
+			name:    "rejects a condition that isn't a boolean",
+			hook:    PuppetContentTemplateHook{Command: "git", When: ".pct_name"},
+			wantErr: "Invalid hook 'git' in 'hooks': the condition evaluated to 'woo' rather than true or false",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layers := []templateEntry{{Info: PuppetContentTemplateInfo{Template: PuppetContentTemplate{
+				Id:    "hooks",
+				Hooks: []PuppetContentTemplateHook{tt.hook},
+			}}}}
+			_, err := planHooks(layers, t.TempDir(), map[string]interface{}{"pct_name": "woo"})
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.wantErr, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package pct
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// PuppetContentTemplateInclude references an installed template to deploy
+// before the including template. Version is a semver constraint, such as
+// `^1.2.0`, and the highest installed version that satisfies it is used. The
+// highest installed version is used when no constraint is given
+type PuppetContentTemplateInclude struct {
+	Id      string `mapstructure:"id" json:"id"`
+	Version string `mapstructure:"version" json:"version,omitempty"`
+}
+
+// resolveIncludes returns the templates to deploy for a template, in order:
+// each of its includes, along with their own includes, followed by the
+// template itself. A template included more than once is only deployed the
+// first time, and an include cycle is an error
+func resolveIncludes(templatePath string, entry templateEntry) ([]templateEntry, error) {
+	var layers []templateEntry
+	seen := make(map[string]bool)
+
+	var resolve func(entry templateEntry, chain []string) error
+	resolve = func(entry templateEntry, chain []string) error {
+		id := entry.Info.Template.Id
+		for _, c := range chain {
+			if c == id {
+				return fmt.Errorf("Template include cycle: %s -> %s", strings.Join(chain, " -> "), id)
+			}
+		}
+		chain = append(chain, id)
+
+		for _, include := range entry.Info.Template.Includes {
+			included, err := findIncludedTemplate(templatePath, include)
+			if err != nil {
+				return fmt.Errorf("Unable to resolve the includes of '%s': %s", id, err)
+			}
+			if err := resolve(included, chain); err != nil {
+				return err
+			}
+		}
+
+		if !seen[entry.Path] {
+			seen[entry.Path] = true
+			layers = append(layers, entry)
+		}
+		return nil
+	}
+
+	err := resolve(entry, nil)
+	return layers, err
+}
+
+// findIncludedTemplate returns the highest installed version of a template
+// that satisfies the version constraint of an include
+func findIncludedTemplate(templatePath string, include PuppetContentTemplateInclude) (templateEntry, error) {
+	var constraint *semver.Constraints
+	if include.Version != "" {
+		c, err := semver.NewConstraint(include.Version)
+		if err != nil {
+			return templateEntry{}, fmt.Errorf("Invalid version constraint '%s' for '%s': %s", include.Version, include.Id, err)
+		}
+		constraint = c
+	}
+
+	for _, entry := range discover(templatePath) {
+		if entry.Info.Template.Id != include.Id {
+			continue
+		}
+		if constraint == nil {
+			return entry, nil
+		}
+		if v, err := semver.NewVersion(entry.Info.Template.Version); err == nil && constraint.Check(v) {
+			return entry, nil
+		}
+	}
+
+	if include.Version != "" {
+		return templateEntry{}, fmt.Errorf("Couldn't find an installed version of '%s' that matches '%s'", include.Id, include.Version)
+	}
+	return templateEntry{}, fmt.Errorf("Couldn't find an installed template that matches '%s'", include.Id)
+}
+
+// layeredParameters returns the parameters declared by a set of templates. A
+// parameter declared by more than one template takes the declaration of the
+// last
+func layeredParameters(layers []templateEntry) []PuppetContentTemplateParameter {
+	var params []PuppetContentTemplateParameter
+	index := make(map[string]int)
+	for _, layer := range layers {
+		for _, p := range layer.Info.Template.Parameters {
+			key := strings.ToLower(p.Name)
+			if i, ok := index[key]; ok {
+				params[i] = p
+				continue
+			}
+			index[key] = len(params)
+			params = append(params, p)
+		}
+	}
+	return params
+}
@@ -0,0 +1,132 @@
+package pct
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// installLayeredTemplate installs a test template with the given extra config
+// under its template key, top level defaults and content files
+func installLayeredTemplate(t *testing.T, templatePath string, id string, version string, templateConfig string, defaults string, files map[string]string) {
+	dir := installTestTemplate(t, templatePath, id, version)
+	config := "---\ntemplate:\n  id: " + id + "\n  type: project\n  version: " + version + "\n" + templateConfig + defaults
+	if err := os.WriteFile(filepath.Join(dir, TemplateConfigFileName), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, "content", name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDeployIncludes(t *testing.T) {
+	cache := t.TempDir()
+	for _, version := range []string{"1.0.0", "1.2.0", "2.0.0"} {
+		installLayeredTemplate(t, cache, "base", version, "", "greeting: hello\nlicense: Apache-2.0\n", map[string]string{
+			"README.md.tmpl":  "base " + version + " {{ .greeting }}",
+			"shared.txt.tmpl": "base",
+		})
+	}
+	installLayeredTemplate(t, cache, "ci", "0.1.0", `  parameters:
+    - name: ci.provider
+      type: enum
+      values: [github, gitlab]
+      default: github
+`, "", map[string]string{
+		"ci.yml.tmpl": "{{ .ci.provider }} {{ .license }}",
+	})
+	installLayeredTemplate(t, cache, "company", "0.1.0", `  includes:
+    - id: base
+      version: ^1.0.0
+    - id: ci
+`, "greeting: hi\n", map[string]string{
+		"shared.txt.tmpl": "company",
+	})
+
+	tmp := t.TempDir()
+	got, err := Deploy(DeployInfo{
+		SelectedTemplate: "company",
+		TemplateCache:    cache,
+		TargetOutputDir:  tmp,
+		TargetName:       "woo",
+		Overrides:        map[string]string{"ci.provider": "gitlab"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(tmp, "woo"),
+		filepath.Join(tmp, "woo", "README.md"),
+		filepath.Join(tmp, "woo", "shared.txt"),
+		filepath.Join(tmp, "woo", "version.txt"),
+		filepath.Join(tmp, "woo", "ci.yml"),
+	}, deployedPaths(got))
+
+	want := map[string]string{
+		"README.md":   "base 1.2.0 hi",
+		"shared.txt":  "company",
+		"version.txt": "0.1.0",
+		"ci.yml":      "gitlab Apache-2.0",
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(tmp, "woo", name))
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(got), name)
+	}
+
+	// parameters declared by included templates are validated
+	_, err = Deploy(DeployInfo{
+		SelectedTemplate: "company",
+		TemplateCache:    cache,
+		TargetOutputDir:  t.TempDir(),
+		TargetName:       "woo",
+		Overrides:        map[string]string{"ci.provider": "jenkins"},
+	})
+	assert.IsType(t, &ParameterError{}, err)
+}
+
+func Test_resolveIncludes(t *testing.T) {
+	cache := t.TempDir()
+	installLayeredTemplate(t, cache, "a", "0.1.0", "  includes:\n    - id: b\n", "", nil)
+	installLayeredTemplate(t, cache, "b", "0.1.0", "  includes:\n    - id: c\n", "", nil)
+	installLayeredTemplate(t, cache, "c", "0.1.0", "  includes:\n    - id: a\n", "", nil)
+	installLayeredTemplate(t, cache, "d", "0.1.0", "  includes:\n    - id: e\n      version: '>= 2'\n", "", nil)
+	installLayeredTemplate(t, cache, "e", "1.0.0", "", "", nil)
+	installLayeredTemplate(t, cache, "f", "0.1.0", "  includes:\n    - id: e\n    - id: g\n", "", nil)
+	installLayeredTemplate(t, cache, "g", "0.1.0", "  includes:\n    - id: e\n", "", nil)
+
+	tests := []struct {
+		id      string
+		want    []string
+		wantErr string
+	}{
+		{id: "a", wantErr: "Template include cycle: a -> b -> c -> a"},
+		{id: "d", wantErr: "Couldn't find an installed version of 'e' that matches '>= 2'"},
+		{id: "e", want: []string{"e"}},
+		{id: "f", want: []string{"e", "g", "f"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			entry, err := findTemplate(cache, tt.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			layers, err := resolveIncludes(cache, entry)
+			if tt.wantErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.wantErr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			var got []string
+			for _, l := range layers {
+				got = append(got, l.Info.Template.Id)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package pct
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// templateIdPattern matches a valid template id: letters, digits, hyphens and
+// underscores. As installed templates are stored in a directory named after
+// their id, it must be a single path segment
+var templateIdPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// Install installs a template from a .tar.gz archive, a local directory or a
+// git repository URL into the template cache at templatePath. Templates are
+// installed by id and version (<templatePath>/<id>/<version>) and an existing
+// install is only replaced when force is true. Returns the directory the
+// template was installed to.
+func Install(source string, templatePath string, force bool) (string, error) {
+	stagingDir, err := os.MkdirTemp("", "pct-install-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(stagingDir)
+
+	var sourceDir string
+	switch {
+	case isArchiveSource(source):
+		log.Debug().Msgf("Extracting archive: %s", source)
+		err = extractArchive(source, stagingDir)
+		sourceDir = stagingDir
+	case isGitSource(source):
+		log.Debug().Msgf("Cloning repository: %s", source)
+		sourceDir = filepath.Join(stagingDir, "repository")
+		err = cloneRepository(source, sourceDir)
+	default:
+		if fi, statErr := os.Stat(source); statErr != nil || !fi.IsDir() {
+			return "", fmt.Errorf("'%s' is not a .tar.gz archive, directory or git repository", source)
+		}
+		sourceDir = source
+	}
+	if err != nil {
+		return "", err
+	}
+
+	templateDir, err := findTemplateRoot(sourceDir)
+	if err != nil {
+		return "", fmt.Errorf("'%s' is not a valid template: %s", source, err)
+	}
+
+	info := readTemplateConfig(filepath.Join(templateDir, TemplateConfigFileName))
+	if info.Template.Id == "" {
+		return "", fmt.Errorf("'%s' is not a valid template: %s does not contain a template.id", source, TemplateConfigFileName)
+	}
+	if info.Template.Version == "" {
+		return "", fmt.Errorf("'%s' is not a valid template: %s does not contain a template.version", source, TemplateConfigFileName)
+	}
+
+	// the id and version name the install directory, so must not be able to
+	// point outside of the template cache
+	if !templateIdPattern.MatchString(info.Template.Id) {
+		return "", fmt.Errorf("'%s' is not a valid template: template.id '%s' must contain only letters, numbers, hyphens and underscores", source, info.Template.Id)
+	}
+	if _, err := semver.NewVersion(info.Template.Version); err != nil {
+		return "", fmt.Errorf("'%s' is not a valid template: template.version '%s' is not a semantic version", source, info.Template.Version)
+	}
+
+	targetDir := filepath.Join(templatePath, info.Template.Id, info.Template.Version)
+	if !isWithinDirectory(templatePath, targetDir) {
+		return "", fmt.Errorf("Unable to install '%s': '%s' is outside of the template path '%s'", source, targetDir, templatePath)
+	}
+	_, err = os.Stat(targetDir)
+	exists := err == nil
+	if exists && !force {
+		return "", fmt.Errorf("Template '%s' version '%s' is already installed at '%s', use --force to overwrite it", info.Template.Id, info.Template.Version, targetDir)
+	}
+
+	// the template is copied next to the target and renamed into place, so a
+	// failed copy leaves any existing install as it was
+	if err := os.MkdirAll(filepath.Dir(targetDir), os.ModePerm); err != nil {
+		return "", err
+	}
+	stagedDir, err := os.MkdirTemp(filepath.Dir(targetDir), "."+info.Template.Version+"-install-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(stagedDir)
+
+	log.Debug().Msgf("Installing '%s' to '%s'", templateDir, targetDir)
+	if err := copyDirectory(templateDir, stagedDir); err != nil {
+		return "", err
+	}
+	if fi, err := os.Stat(templateDir); err == nil {
+		if err := os.Chmod(stagedDir, fi.Mode().Perm()); err != nil {
+			return "", err
+		}
+	}
+
+	if !exists {
+		return targetDir, os.Rename(stagedDir, targetDir)
+	}
+
+	log.Debug().Msgf("Replacing existing install: %s", targetDir)
+	replacedDir := stagedDir + "-replaced"
+	if err := os.Rename(targetDir, replacedDir); err != nil {
+		return "", err
+	}
+	if err := os.Rename(stagedDir, targetDir); err != nil {
+		os.Rename(replacedDir, targetDir) //nolint:errcheck
+		return "", err
+	}
+	os.RemoveAll(replacedDir) //nolint:errcheck
+
+	return targetDir, nil
+}
+
+func isArchiveSource(source string) bool {
+	return strings.HasSuffix(source, ".tar.gz") || strings.HasSuffix(source, ".tgz")
+}
+
+func isGitSource(source string) bool {
+	for _, prefix := range []string{"http://", "https://", "git://", "ssh://", "file://", "git@"} {
+		if strings.HasPrefix(source, prefix) {
+			return true
+		}
+	}
+	return strings.HasSuffix(source, ".git")
+}
+
+// findTemplateRoot returns the directory containing the pct-config.yml, which
+// is either the given directory or its only child directory, as is common for
+// archives and repositories that wrap the template in a folder
+func findTemplateRoot(dir string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, TemplateConfigFileName)); err == nil {
+		return dir, nil
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "*", TemplateConfigFileName))
+	if len(matches) == 1 {
+		return filepath.Dir(matches[0]), nil
+	}
+	if len(matches) > 1 {
+		return "", fmt.Errorf("found more than one %s", TemplateConfigFileName)
+	}
+	return "", fmt.Errorf("could not find a %s", TemplateConfigFileName)
+}
+
+func extractArchive(archive string, targetDir string) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("Unable to read archive '%s': %s", archive, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Unable to read archive '%s': %s", archive, err)
+		}
+
+		target := filepath.Join(targetDir, filepath.FromSlash(header.Name))
+		if !isWithinDirectory(targetDir, target) {
+			return fmt.Errorf("Archive '%s' contains an invalid path: %s", archive, header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.ModePerm); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+				return err
+			}
+			if err := writeFile(target, tr, header.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		default:
+			log.Debug().Msgf("Skipping unsupported archive entry: %s", header.Name)
+		}
+	}
+}
+
+func cloneRepository(url string, targetDir string) error {
+	cmd := exec.Command("git", "clone", "--depth", "1", url, targetDir)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("Unable to clone '%s': %s", url, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// copyDirectory recursively copies the contents of sourceDir to targetDir,
+// skipping any version control metadata
+func copyDirectory(sourceDir string, targetDir string) error {
+	return filepath.WalkDir(sourceDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		rel, err := filepath.Rel(sourceDir, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(targetDir, rel)
+
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(target, fi.Mode().Perm())
+		}
+		if !fi.Mode().IsRegular() {
+			log.Debug().Msgf("Skipping non regular file: %s", path)
+			return nil
+		}
+
+		source, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer source.Close()
+		return writeFile(target, source, fi.Mode().Perm())
+	})
+}
+
+func writeFile(path string, r io.Reader, perm os.FileMode) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, r); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// isWithinDirectory reports whether path is dir or resides beneath it
+func isWithinDirectory(dir string, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
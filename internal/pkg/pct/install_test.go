@@ -0,0 +1,207 @@
+package pct
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// createArchive writes the contents of sourceDir to a .tar.gz archive, nested
+// beneath prefix
+func createArchive(t *testing.T, sourceDir string, prefix string) string {
+	archive := filepath.Join(t.TempDir(), "template.tar.gz")
+	file, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	defer gz.Close()
+	tw := tar.NewWriter(gz)
+	defer tw.Close()
+
+	err = filepath.Walk(sourceDir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(sourceDir, path)
+		header, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(filepath.Join(prefix, rel))
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return archive
+}
+
+// createBareRepository commits the contents of sourceDir to a new bare git
+// repository and returns its file:// url
+func createBareRepository(t *testing.T, sourceDir string) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	workDir := t.TempDir()
+	bareDir := filepath.Join(t.TempDir(), "template.git")
+	if err := copyDirectory(sourceDir, workDir); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"-C", workDir, "init", "-q"},
+		{"-C", workDir, "add", "."},
+		{"-C", workDir, "-c", "user.name=pct", "-c", "user.email=pct@example.com", "commit", "-q", "-m", "template"},
+		{"clone", "-q", "--bare", workDir, bareDir},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s", args, out)
+		}
+	}
+	return "file://" + filepath.ToSlash(bareDir)
+}
+
+func TestInstall(t *testing.T) {
+	goodProject := "testdata/examples/good-project"
+
+	noId := t.TempDir()
+	err := os.WriteFile(filepath.Join(noId, TemplateConfigFileName), []byte("---\ntemplate:\n  type: project\n  version: 0.1.0\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// writeConfig writes a template with the given id and version to a new
+	// directory, returning its path
+	writeConfig := func(id string, version string) string {
+		dir := t.TempDir()
+		config := "---\ntemplate:\n  id: " + id + "\n  type: project\n  version: " + version + "\n"
+		if err := os.WriteFile(filepath.Join(dir, TemplateConfigFileName), []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+
+	tests := []struct {
+		name    string
+		source  string
+		force   bool
+		setup   func(templatePath string)
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "installs a template from a local directory",
+			source: goodProject,
+			want:   filepath.Join("good-project", "0.1.0"),
+		},
+		{
+			name:   "installs a template from a .tar.gz archive",
+			source: createArchive(t, goodProject, "good-project"),
+			want:   filepath.Join("good-project", "0.1.0"),
+		},
+		{
+			name:   "installs a template from a git repository",
+			source: createBareRepository(t, goodProject),
+			want:   filepath.Join("good-project", "0.1.0"),
+		},
+		{
+			name:   "refuses to overwrite an existing install",
+			source: goodProject,
+			setup: func(templatePath string) {
+				Install(goodProject, templatePath, false) //nolint:errcheck
+			},
+			wantErr: "already installed",
+		},
+		{
+			name:   "overwrites an existing install when forced",
+			source: goodProject,
+			force:  true,
+			setup: func(templatePath string) {
+				Install(goodProject, templatePath, false) //nolint:errcheck
+				stale := filepath.Join(templatePath, "good-project", "0.1.0", "content", "stale.txt")
+				os.WriteFile(stale, []byte("stale"), 0644) //nolint:errcheck
+			},
+			want: filepath.Join("good-project", "0.1.0"),
+		},
+		{
+			name:    "returns an error for a directory without a pct-config.yml",
+			source:  "testdata/examples",
+			wantErr: "found more than one pct-config.yml",
+		},
+		{
+			name:    "returns an error for a template without an id",
+			source:  noId,
+			wantErr: "does not contain a template.id",
+		},
+		{
+			name:    "returns an error for an id that traverses out of the template path",
+			source:  writeConfig("../../victim", "0.1.0"),
+			force:   true,
+			wantErr: "template.id '../../victim' must contain only letters, numbers, hyphens and underscores",
+		},
+		{
+			name:    "returns an error for a version that traverses out of the template path",
+			source:  writeConfig("good-project", "../../../victim"),
+			force:   true,
+			wantErr: "template.version '../../../victim' is not a semantic version",
+		},
+		{
+			name:    "returns an error for a source that does not exist",
+			source:  "testdata/does-not-exist",
+			wantErr: "is not a .tar.gz archive, directory or git repository",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templatePath := t.TempDir()
+			if tt.setup != nil {
+				tt.setup(templatePath)
+			}
+
+			got, err := Install(tt.source, templatePath, tt.force)
+			if tt.wantErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, filepath.Join(templatePath, tt.want), got)
+			assert.FileExists(t, filepath.Join(got, TemplateConfigFileName))
+			assert.FileExists(t, filepath.Join(got, "content", "goodfile.txt.tmpl"))
+			_, err = os.Stat(filepath.Join(got, ".git"))
+			assert.True(t, os.IsNotExist(err))
+			_, err = os.Stat(filepath.Join(got, "content", "stale.txt"))
+			assert.True(t, os.IsNotExist(err))
+
+			tmpls, _ := List(templatePath, "good-project")
+			assert.Len(t, tmpls, 1)
+
+			// nothing is left behind from staging or replacing the install
+			entries, _ := os.ReadDir(filepath.Dir(got))
+			assert.Len(t, entries, 1)
+		})
+	}
+}
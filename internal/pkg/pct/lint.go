@@ -0,0 +1,400 @@
+package pct
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/Masterminds/semver/v3"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+// TemplateTypes lists the values accepted for template.type
+var TemplateTypes = []string{"project", "item"}
+
+// TemplateProblem is a problem found in a template by LintTemplate. Line and
+// Column are zero when unknown
+type TemplateProblem struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (p TemplateProblem) String() string {
+	return FileError{TemplatePath: p.File, Line: p.Line, Column: p.Column, Message: p.Message}.Error()
+}
+
+// yamlErrorPattern matches the line yaml reports in syntax errors
+var yamlErrorPattern = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// LintTemplate checks a template directory for the problems that would
+// otherwise only be found when it is deployed: the required fields of its
+// pct-config.yml and the validity of its parameters, rules, modes, includes
+// and hooks, the syntax of every .tmpl file, and the placeholders in the
+// names of its content. Every problem found is returned, ordered by file and
+// position
+func LintTemplate(templateDir string) ([]TemplateProblem, error) {
+	if fi, err := os.Stat(templateDir); err != nil || !fi.IsDir() {
+		return nil, fmt.Errorf("'%s' is not a directory", templateDir)
+	}
+
+	l := linter{dir: templateDir}
+	info, ok := l.lintConfig()
+	if ok {
+		l.lintContent(info)
+	}
+
+	sort.SliceStable(l.problems, func(i, j int) bool {
+		a, b := l.problems[i], l.problems[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return l.problems, nil
+}
+
+// FormatTemplateProblems writes the problems found in a template to w in text
+// or json format
+func FormatTemplateProblems(w io.Writer, problems []TemplateProblem, format string) error {
+	switch format {
+	case "text":
+		for _, p := range problems {
+			fmt.Fprintln(w, p)
+		}
+	case "json":
+		if problems == nil {
+			problems = []TemplateProblem{}
+		}
+		j := jsoniter.ConfigFastest
+		prettyJSON, err := j.MarshalIndent(problems, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\n", prettyJSON)
+	default:
+		return fmt.Errorf("Unknown format '%s', expected text or json", format)
+	}
+	return nil
+}
+
+type linter struct {
+	dir      string
+	problems []TemplateProblem
+}
+
+func (l *linter) report(file string, line int, format string, args ...interface{}) {
+	l.problems = append(l.problems, TemplateProblem{File: file, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// reportError reports an error from text/template, with its position
+func (l *linter) reportError(file string, err error) {
+	fe := newFileError(PuppetContentTemplateFileInfo{TemplatePath: file}, err)
+	l.problems = append(l.problems, TemplateProblem{File: file, Line: fe.Line, Column: fe.Column, Message: fe.Message})
+}
+
+// lintConfig checks the pct-config.yml of the template, returning the parsed
+// config and whether it could be read
+func (l *linter) lintConfig() (PuppetContentTemplateInfo, bool) {
+	configFile := filepath.Join(l.dir, TemplateConfigFileName)
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		l.report(configFile, 0, "the template must contain a %s", TemplateConfigFileName)
+		return PuppetContentTemplateInfo{}, false
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		line := 0
+		msg := err.Error()
+		if m := yamlErrorPattern.FindStringSubmatch(msg); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			msg = m[2]
+		}
+		l.report(configFile, line, "invalid YAML: %s", msg)
+		return PuppetContentTemplateInfo{}, false
+	}
+
+	var info PuppetContentTemplateInfo
+	v := viper.New()
+	v.SetConfigType("yml")
+	err = v.ReadConfig(bytes.NewReader(data))
+	if err == nil {
+		err = v.Unmarshal(&info)
+	}
+	if err != nil {
+		l.report(configFile, configLine(data, "template"), "unable to read the template configuration: %s", err)
+		return PuppetContentTemplateInfo{}, false
+	}
+
+	tmpl := info.Template
+	line := func(key string) int {
+		if n := configLine(data, key); n > 0 {
+			return n
+		}
+		return configLine(data, "template")
+	}
+
+	required := map[string]string{"id": tmpl.Id, "type": tmpl.Type, "display": tmpl.Display, "version": tmpl.Version}
+	for _, key := range []string{"id", "type", "display", "version"} {
+		if required[key] == "" {
+			l.report(configFile, line(key), "template.%s is required", key)
+		}
+	}
+	if tmpl.Type != "" && !isTemplateType(tmpl.Type) {
+		l.report(configFile, line("type"), "template.type '%s' must be one of: %s", tmpl.Type, strings.Join(TemplateTypes, ", "))
+	}
+	if tmpl.Version != "" {
+		if _, err := semver.StrictNewVersion(tmpl.Version); err != nil {
+			// YAML reads a version such as 1.0 as a number
+			version := tmpl.Version
+			if n := configLine(data, "version"); n > 0 {
+				version = strings.Trim(strings.TrimSpace(strings.SplitN(strings.Split(string(data), "\n")[n-1], ":", 2)[1]), `"'`)
+			}
+			l.report(configFile, line("version"), "template.version '%s' is not a semantic version", version)
+		}
+	}
+
+	for _, p := range tmpl.Parameters {
+		if err := parameterDeclarationError(p); err != nil {
+			l.report(configFile, line("parameters"), "%s", err)
+			continue
+		}
+		if p.Default != nil {
+			if err := validateParameter(p, p.Default); err != nil {
+				l.report(configFile, line("parameters"), "parameter %s has an invalid default: %s", p.Name, err)
+			}
+		}
+	}
+
+	for _, rule := range tmpl.Rules {
+		if _, err := filepath.Match(rule.Path, ""); err != nil || rule.Path == "" {
+			l.report(configFile, line("rules"), "rule path '%s' must be a glob relative to the content directory", rule.Path)
+		}
+		if err := parseCondition(rule.When); err != nil {
+			l.report(configFile, line("rules"), "rule for '%s' has an invalid condition: %s", rule.Path, err)
+		}
+	}
+
+	for _, m := range tmpl.Modes {
+		if _, err := parseMode(m.Mode); err != nil {
+			l.report(configFile, line("modes"), "mode for '%s': %s", m.Path, err)
+		}
+	}
+
+	for _, include := range tmpl.Includes {
+		if include.Id == "" {
+			l.report(configFile, line("includes"), "an include has no id")
+		}
+		if include.Version != "" {
+			if _, err := semver.NewConstraint(include.Version); err != nil {
+				l.report(configFile, line("includes"), "include '%s' has an invalid version constraint '%s': %s", include.Id, include.Version, err)
+			}
+		}
+	}
+
+	for _, hook := range tmpl.Hooks {
+		if hook.Command == "" {
+			l.report(configFile, line("hooks"), "a hook has no command")
+		}
+		if hook.When != "" {
+			if err := parseCondition(hook.When); err != nil {
+				l.report(configFile, line("hooks"), "hook '%s' has an invalid condition: %s", hook.Command, err)
+			}
+		}
+	}
+
+	info.Defaults = raw
+	delete(info.Defaults, "template")
+	return info, true
+}
+
+// lintContent checks the partials of the template, and the syntax and
+// name of every file in its content directory. Templates that don't include
+// others are also checked for references to undefined variables
+func (l *linter) lintContent(info PuppetContentTemplateInfo) {
+	contentDir := filepath.Join(l.dir, "content")
+	if fi, err := os.Stat(contentDir); err != nil || !fi.IsDir() {
+		l.report(contentDir, 0, "the template must contain a content directory")
+		return
+	}
+
+	entry := templateEntry{Path: l.dir, Info: info}
+	partials, err := collectPartials([]templateEntry{entry})
+	if err != nil {
+		l.report(filepath.Join(l.dir, PartialsDirName), 0, "%s", err)
+	}
+	partialTemplates := template.New("partials").Funcs(templateFuncs())
+	for name, path := range partials {
+		if _, err := l.parseFile(partialTemplates.New(name), path); err != nil {
+			l.reportError(path, err)
+		}
+	}
+
+	// variables from included templates aren't known, so undefined
+	// variables can only be reported in templates that don't include others
+	standalone := len(info.Template.Includes) == 0
+	config := processLayeredConfiguration(DeployInfo{TargetName: "example"}, []templateEntry{entry})
+	// parameters without a default are answered when the template is
+	// deployed, so are known even though they have no value yet
+	for _, p := range info.Template.Parameters {
+		if _, ok := lookupValue(config, p.Name); !ok && p.Name != "" {
+			setValue(config, strings.ToLower(p.Name), "")
+		}
+	}
+	outputDir := filepath.Join(os.TempDir(), "example")
+
+	err = filepath.WalkDir(contentDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			l.report(path, 0, "%s", err)
+			return nil
+		}
+		rel, _ := filepath.Rel(contentDir, path)
+		if rel == "." {
+			return nil
+		}
+
+		if _, err := resolveTargetPath(outputDir, rel, config, standalone); err != nil {
+			l.report(path, 0, "invalid path: %s", newFileError(PuppetContentTemplateFileInfo{}, err).Message)
+		}
+
+		if d.IsDir() || !strings.HasSuffix(path, ".tmpl") || matchesAny(rel, info.Template.CopyOnly) {
+			return nil
+		}
+		tmpl, err := partialTemplates.Clone()
+		if err == nil {
+			tmpl, err = l.parseFile(tmpl.New(filepath.Base(path)), path)
+		}
+		if err != nil {
+			l.reportError(path, err)
+			return nil
+		}
+		if standalone {
+			for _, name := range templateCalls(tmpl.Tree.Root) {
+				if tmpl.Lookup(name) == nil {
+					l.report(path, 0, "no partial named '%s' in %s", name, filepath.Join(l.dir, PartialsDirName))
+				}
+			}
+			undefined, err := undefinedReferences(path, config)
+			if err != nil {
+				undefined = append(undefined, err)
+			}
+			for _, u := range undefined {
+				l.reportError(path, u)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		l.report(contentDir, 0, "%s", err)
+	}
+}
+
+func (l *linter) parseFile(tmpl *template.Template, path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.Parse(string(data))
+}
+
+// templateCalls returns the names of the templates a parse tree executes with
+// the template action
+func templateCalls(node parse.Node) []string {
+	var names []string
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			names = append(names, templateCalls(child)...)
+		}
+	case *parse.IfNode:
+		names = append(templateCalls(n.List), templateCalls(n.ElseList)...)
+	case *parse.RangeNode:
+		names = append(templateCalls(n.List), templateCalls(n.ElseList)...)
+	case *parse.WithNode:
+		names = append(templateCalls(n.List), templateCalls(n.ElseList)...)
+	case *parse.TemplateNode:
+		names = append(names, n.Name)
+	}
+	return names
+}
+
+func isTemplateType(t string) bool {
+	for _, valid := range TemplateTypes {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCondition checks the syntax of a rule or hook condition
+func parseCondition(condition string) error {
+	if !strings.Contains(condition, "{{") {
+		condition = "{{ " + condition + " }}"
+	}
+	_, err := template.New("when").Funcs(templateFuncs()).Parse(condition)
+	return err
+}
+
+// parameterDeclarationError checks a parameter declares a name, a known type,
+// the values of an enum and a valid pattern
+func parameterDeclarationError(p PuppetContentTemplateParameter) error {
+	if p.Name == "" {
+		return fmt.Errorf("a parameter has no name")
+	}
+	switch p.Type {
+	case ParameterTypeString, ParameterTypeInt, ParameterTypeBool, ParameterTypeList, "":
+	case ParameterTypeEnum:
+		if len(p.Values) == 0 {
+			return fmt.Errorf("parameter %s is an enum but has no values", p.Name)
+		}
+	default:
+		return fmt.Errorf("parameter %s has an unknown type '%s'", p.Name, p.Type)
+	}
+	if p.Pattern != "" {
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return fmt.Errorf("parameter %s has an invalid pattern '%s': %s", p.Name, p.Pattern, err)
+		}
+	}
+	return nil
+}
+
+// configLine returns the line number of a key of the template section of a
+// pct-config.yml, or of the template section itself, or zero when not found
+func configLine(data []byte, key string) int {
+	section := regexp.MustCompile(`^template\s*:`)
+	keyPattern := regexp.MustCompile(`^\s+` + regexp.QuoteMeta(key) + `\s*:`)
+	inTemplate := false
+	for i, line := range strings.Split(string(data), "\n") {
+		switch {
+		case section.MatchString(line):
+			if key == "template" {
+				return i + 1
+			}
+			inTemplate = true
+		case len(line) > 0 && line[0] != ' ' && line[0] != '\t' && line[0] != '#':
+			inTemplate = false
+		case inTemplate && keyPattern.MatchString(line):
+			return i + 1
+		}
+	}
+	return 0
+}
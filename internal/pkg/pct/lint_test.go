@@ -0,0 +1,160 @@
+package pct
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTemplateFiles writes files, keyed by their slash separated path, into
+// a template directory
+func writeTemplateFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLintTemplate(t *testing.T) {
+	for _, example := range []string{"full-project", "param-project", "replace-thing"} {
+		t.Run(example, func(t *testing.T) {
+			problems, err := LintTemplate(filepath.Join("testdata", "examples", example))
+			assert.NoError(t, err)
+			assert.Empty(t, problems)
+		})
+	}
+
+	t.Run("reports variables only given as overrides", func(t *testing.T) {
+		problems, err := LintTemplate(filepath.Join("testdata", "examples", "good-project"))
+		assert.NoError(t, err)
+		assert.Equal(t, []TemplateProblem{{
+			File:    filepath.Join("testdata", "examples", "good-project", "content", "goodfile.txt.tmpl"),
+			Line:    1,
+			Column:  10,
+			Message: "undefined variable .example_data",
+		}}, problems)
+	})
+
+	t.Run("reports every problem", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTemplateFiles(t, dir, map[string]string{
+			TemplateConfigFileName: `---
+template:
+  id: broken
+  type: thing
+  version: 1.0
+  parameters:
+    - name: license
+      type: enum
+    - name: port
+      type: int
+      default: eighty
+  rules:
+    - path: ci.yml
+      when: (eq .ci.provider "github"
+  includes:
+    - id: base
+      version: not a version
+
+name: example
+`,
+			"content/README.md.tmpl":        "# {{ .name\n",
+			"content/LICENSE.tmpl":          "{{template \"license_header\" .}}",
+			"content/{{ .missing }}.txt":    "",
+			"content/{{ .name }}.rb.tmpl":   "{{ .name | upper }}",
+			"content/copied.erb.tmpl":       "<%= {{ %>",
+			"partials/license_header.tmpl":  "{{ end }}",
+			"content/ok/{{ pct_name }}.txt": "",
+		})
+		// a template that includes others can use their partials and variables
+		problems, err := LintTemplate(dir)
+		assert.NoError(t, err)
+
+		var got []string
+		for _, p := range problems {
+			rel, _ := filepath.Rel(dir, p.File)
+			p.File = filepath.ToSlash(rel)
+			got = append(got, p.String())
+		}
+		assert.Equal(t, []string{
+			"content/README.md.tmpl:2: unclosed action started at README.md.tmpl:1",
+			"content/copied.erb.tmpl:1: unexpected \"%\" in command",
+			"partials/license_header.tmpl:1: unexpected {{end}}",
+			"pct-config.yml:2: template.display is required",
+			"pct-config.yml:4: template.type 'thing' must be one of: project, item",
+			"pct-config.yml:5: template.version '1.0' is not a semantic version",
+			"pct-config.yml:6: parameter license is an enum but has no values",
+			"pct-config.yml:6: parameter port has an invalid default: 'eighty' is not an integer",
+			"pct-config.yml:12: rule for 'ci.yml' has an invalid condition: template: when:1: unclosed left paren",
+			"pct-config.yml:15: include 'base' has an invalid version constraint 'not a version': improper constraint: not a version",
+		}, got)
+	})
+
+	t.Run("reports undefined variables and partials of standalone templates", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTemplateFiles(t, dir, map[string]string{
+			TemplateConfigFileName:       "---\ntemplate:\n  id: standalone\n  type: item\n  display: Standalone\n  version: 0.1.0\n  copy_only:\n    - \"*.erb\"\n  parameters:\n    - name: owner\n\npuppet_module:\n  license: MIT\n",
+			"content/{{ .missing }}.txt": "",
+			"content/LICENSE.tmpl":       "{{template \"license_header\" .}}",
+			"content/copied.erb.tmpl":    "<%= {{ %>",
+			"content/metadata.json.tmpl": "{{ .owner }} {{ .pct_name }}\n{{ .puppet_module.license }} {{ .puppet_module.licence }}\n",
+		})
+		problems, err := LintTemplate(dir)
+		assert.NoError(t, err)
+
+		var got []string
+		for _, p := range problems {
+			rel, _ := filepath.Rel(dir, p.File)
+			got = append(got, filepath.ToSlash(rel)+": "+p.Message)
+		}
+		assert.Equal(t, []string{
+			"content/LICENSE.tmpl: no partial named 'license_header' in " + filepath.Join(dir, PartialsDirName),
+			"content/metadata.json.tmpl: undefined variable .puppet_module.licence",
+			`content/{{ .missing }}.txt: invalid path: executing "{{ .missing }}.txt" at <.missing>: map has no entry for key "missing"`,
+		}, got)
+	})
+
+	t.Run("requires a pct-config.yml and content", func(t *testing.T) {
+		dir := t.TempDir()
+		problems, err := LintTemplate(dir)
+		assert.NoError(t, err)
+		assert.Equal(t, []TemplateProblem{{File: filepath.Join(dir, TemplateConfigFileName), Message: "the template must contain a pct-config.yml"}}, problems)
+
+		writeTemplateFiles(t, dir, map[string]string{TemplateConfigFileName: "template: [\n"})
+		problems, _ = LintTemplate(dir)
+		if assert.Len(t, problems, 1) {
+			assert.Contains(t, problems[0].Message, "invalid YAML")
+		}
+	})
+
+	t.Run("requires a directory", func(t *testing.T) {
+		_, err := LintTemplate(filepath.Join(t.TempDir(), "missing"))
+		assert.Error(t, err)
+	})
+}
+
+func TestFormatTemplateProblems(t *testing.T) {
+	problems := []TemplateProblem{{File: "pct-config.yml", Line: 2, Message: "template.display is required"}}
+
+	var text bytes.Buffer
+	assert.NoError(t, FormatTemplateProblems(&text, problems, "text"))
+	assert.Equal(t, "pct-config.yml:2: template.display is required\n", text.String())
+
+	var out bytes.Buffer
+	assert.NoError(t, FormatTemplateProblems(&out, nil, "json"))
+	var got []TemplateProblem
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &got))
+	assert.Empty(t, got)
+	assert.NotNil(t, got)
+
+	assert.Error(t, FormatTemplateProblems(&out, problems, "yaml"))
+}
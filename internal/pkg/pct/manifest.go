@@ -0,0 +1,174 @@
+package pct
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ManifestFileName is the path, relative to the output directory, of the
+// manifest Deploy writes to record how the output was generated
+var ManifestFileName = filepath.Join(".pct", "manifest.json")
+
+// Manifest records each template deployed to an output directory
+type Manifest struct {
+	Templates []ManifestTemplate `json:"templates"`
+}
+
+// ManifestTemplate records a deployment of a template: the version and source
+// of the template and the templates it included, the template data supplied
+// by the user and the SHA-256 checksum of every file the template generated, keyed by
+// its slash separated path relative to the output directory. A file whose
+// checksum still matches is unchanged since it was generated
+type ManifestTemplate struct {
+	Id       string            `json:"id"`
+	Version  string            `json:"version"`
+	Source   string            `json:"source,omitempty"`
+	Includes []ManifestInclude `json:"includes,omitempty"`
+	// Variables holds the resolved values of the name, answers and overrides
+	// given for the deployment. Defaults of the template and values from the
+	// machine are left out, so a newer version of the template can change them
+	Variables map[string]interface{} `json:"variables"`
+	Files     map[string]string      `json:"files"`
+}
+
+// ManifestInclude records the version of an included template
+type ManifestInclude struct {
+	Id      string `json:"id"`
+	Version string `json:"version"`
+}
+
+// Template returns the manifest entry of a template
+func (m Manifest) Template(id string) (ManifestTemplate, bool) {
+	for _, t := range m.Templates {
+		if t.Id == id {
+			return t, true
+		}
+	}
+	return ManifestTemplate{}, false
+}
+
+// ReadManifest reads the manifest of an output directory. An empty manifest is
+// returned when the directory doesn't have one
+func ReadManifest(outputDir string) (Manifest, error) {
+	var m Manifest
+	data, err := os.ReadFile(filepath.Join(outputDir, ManifestFileName))
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("Unable to read %s: %s", filepath.Join(outputDir, ManifestFileName), err)
+	}
+	return m, nil
+}
+
+// checksum returns the hex encoded SHA-256 checksum of content
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// renderManifest records the planned deployment in the manifest of the output
+// directory, replacing any earlier record of the same template. The checksum
+// of every planned file is recorded, including those kept by the skip
+// conflict policy, so a file that differs from its checksum is known to have
+// been changed since the template generated it
+func renderManifest(plan deployPlan, layers []templateEntry) (deployFile, error) {
+	outputDir := plan.Info.TargetOutputDir
+	m, err := ReadManifest(outputDir)
+	if err != nil {
+		return deployFile{}, err
+	}
+
+	tmpl := plan.Template.Info.Template
+	record := ManifestTemplate{
+		Id:        tmpl.Id,
+		Version:   tmpl.Version,
+		Source:    tmpl.URL,
+		Variables: suppliedVariables(plan.Info, plan.Config),
+		Files:     make(map[string]string),
+	}
+	for _, layer := range layers[:len(layers)-1] {
+		record.Includes = append(record.Includes, ManifestInclude{Id: layer.Info.Template.Id, Version: layer.Info.Template.Version})
+	}
+	for _, f := range plan.Files {
+		if f.IsDirectory {
+			continue
+		}
+		rel, err := filepath.Rel(outputDir, f.TargetFilePath)
+		if err != nil {
+			return deployFile{}, err
+		}
+		record.Files[filepath.ToSlash(rel)] = checksum([]byte(f.Content))
+	}
+
+	replaced := false
+	for i, t := range m.Templates {
+		if t.Id == record.Id {
+			m.Templates[i] = record
+			replaced = true
+		}
+	}
+	if !replaced {
+		m.Templates = append(m.Templates, record)
+		sort.SliceStable(m.Templates, func(i, j int) bool { return m.Templates[i].Id < m.Templates[j].Id })
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return deployFile{}, fmt.Errorf("Unable to write %s: %s", ManifestFileName, err)
+	}
+
+	target := filepath.Join(outputDir, ManifestFileName)
+	f := deployFile{
+		PuppetContentTemplateFileInfo: PuppetContentTemplateFileInfo{
+			TargetFilePath: target,
+			TargetDir:      filepath.Dir(target),
+			TargetFile:     filepath.Base(target),
+			Mode:           defaultFileMode,
+		},
+		Content: string(data) + "\n",
+	}
+	f.Action = plannedAction(f)
+	return f, nil
+}
+
+// suppliedVariables returns the resolved values of the template data the user
+// supplied for a deployment: the name, any answers from a file or prompts and
+// any --set overrides
+func suppliedVariables(info DeployInfo, config map[string]interface{}) map[string]interface{} {
+	keys := append([]string{"pct_name"}, answerKeys("", info.Answers)...)
+	for key := range info.Overrides {
+		keys = append(keys, key)
+	}
+
+	vars := make(map[string]interface{})
+	for _, key := range keys {
+		if value, ok := lookupValue(config, key); ok {
+			setValue(vars, strings.ToLower(key), value)
+		}
+	}
+	return vars
+}
+
+// answerKeys returns the dotted key of every value in a set of answers
+func answerKeys(prefix string, answers map[string]interface{}) []string {
+	var keys []string
+	for k, v := range answers {
+		key := prefix + k
+		if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+			keys = append(keys, answerKeys(key+".", nested)...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
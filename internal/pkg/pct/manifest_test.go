@@ -0,0 +1,106 @@
+package pct
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployManifest(t *testing.T) {
+	cache := t.TempDir()
+	installLayeredTemplate(t, cache, "base", "1.0.0", "", "", map[string]string{
+		"README.md.tmpl": "base {{ .pct_name }}",
+	})
+	installLayeredTemplate(t, cache, "company", "0.2.0", "  url: https://example.com/company\n  includes:\n    - id: base\n", "greeting: hi\n", map[string]string{
+		"greeting.txt.tmpl": "{{ .greeting }}",
+	})
+	installLayeredTemplate(t, cache, "ci", "0.1.0", "", "", map[string]string{
+		"ci.yml.tmpl": "ci",
+	})
+
+	tmp := t.TempDir()
+	info := DeployInfo{
+		SelectedTemplate: "company",
+		TemplateCache:    cache,
+		TargetOutputDir:  tmp,
+		TargetName:       "woo",
+	}
+	got, err := Deploy(info)
+	assert.NoError(t, err)
+	for _, f := range got.Files {
+		assert.NotContains(t, f.Path, ".pct", "the manifest isn't listed as deployed content")
+	}
+
+	output := filepath.Join(tmp, "woo")
+	m, err := ReadManifest(output)
+	assert.NoError(t, err)
+	if assert.Len(t, m.Templates, 1) {
+		company := m.Templates[0]
+		assert.Equal(t, "company", company.Id)
+		assert.Equal(t, "0.2.0", company.Version)
+		assert.Equal(t, "https://example.com/company", company.Source)
+		assert.Equal(t, []ManifestInclude{{Id: "base", Version: "1.0.0"}}, company.Includes)
+		// only values supplied by the user are recorded, not defaults
+		assert.Equal(t, map[string]interface{}{"pct_name": "woo"}, company.Variables)
+		assert.Equal(t, map[string]string{
+			"README.md":    checksum([]byte("base woo")),
+			"greeting.txt": checksum([]byte("hi")),
+			"version.txt":  checksum([]byte("0.2.0")),
+		}, company.Files)
+	}
+
+	// the manifest is never a conflict or a difference
+	info.Overrides = map[string]string{"unused": "changed"}
+	info.Answers = map[string]interface{}{"puppet_module": map[string]interface{}{"license": "MIT"}}
+	var diff bytes.Buffer
+	changed, err := Diff(info, &diff)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Empty(t, diff.String())
+	_, err = Deploy(info)
+	assert.NoError(t, err)
+	m, _ = ReadManifest(output)
+	assert.Equal(t, map[string]interface{}{
+		"pct_name":      "woo",
+		"unused":        "changed",
+		"puppet_module": map[string]interface{}{"license": "MIT"},
+	}, m.Templates[0].Variables)
+
+	// other templates deployed to the same output are recorded alongside
+	_, err = Deploy(DeployInfo{
+		SelectedTemplate: "ci",
+		TemplateCache:    cache,
+		TargetOutputDir:  tmp,
+		TargetName:       "woo",
+		OnConflict:       ConflictSkip,
+	})
+	assert.NoError(t, err)
+	m, err = ReadManifest(output)
+	assert.NoError(t, err)
+	if assert.Len(t, m.Templates, 2) {
+		assert.Equal(t, "ci", m.Templates[0].Id)
+		assert.Equal(t, "company", m.Templates[1].Id)
+	}
+	company, ok := m.Template("company")
+	assert.True(t, ok)
+	assert.Len(t, company.Files, 3)
+}
+
+func TestReadManifest(t *testing.T) {
+	tmp := t.TempDir()
+	m, err := ReadManifest(tmp)
+	assert.NoError(t, err)
+	assert.Empty(t, m.Templates)
+
+	if err := os.MkdirAll(filepath.Join(tmp, ".pct"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, ManifestFileName), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadManifest(tmp)
+	assert.Error(t, err)
+}
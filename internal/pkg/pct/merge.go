@@ -0,0 +1,126 @@
+package pct
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// mergeLabels names the three versions of a file in conflict markers
+type mergeLabels struct {
+	Current string
+	Base    string
+	Other   string
+}
+
+// merge3 performs a three-way merge of the changes from base to current and
+// from base to other, line by line as diff3 does. Regions changed on only one
+// side take that change, and regions changed differently on both sides are
+// written with diff3 style conflict markers. Returns the merged content and
+// the number of conflicts
+func merge3(base string, current string, other string, labels mergeLabels) (string, int) {
+	o, a, b := splitLines(base), splitLines(current), splitLines(other)
+	ma, mb := matchedLines(o, a), matchedLines(o, b)
+
+	var out strings.Builder
+	conflicts := 0
+	lo, la, lb := 0, 0, 0
+	for lo < len(o) || la < len(a) || lb < len(b) {
+		// a stable region, where all three agree
+		n := 0
+		for lo+n < len(o) {
+			ia, okA := ma[lo+n]
+			ib, okB := mb[lo+n]
+			if !okA || !okB || ia != la+n || ib != lb+n {
+				break
+			}
+			n++
+		}
+		if n > 0 {
+			writeLines(&out, o[lo:lo+n])
+			lo, la, lb = lo+n, la+n, lb+n
+			continue
+		}
+
+		// an unstable region, up to the next line of base both sides kept
+		next, na, nb := len(o), len(a), len(b)
+		for i := lo; i < len(o); i++ {
+			ia, okA := ma[i]
+			ib, okB := mb[i]
+			if okA && okB {
+				next, na, nb = i, ia, ib
+				break
+			}
+		}
+		co, ca, cb := o[lo:next], a[la:na], b[lb:nb]
+		switch {
+		case equalLines(co, ca):
+			writeLines(&out, cb)
+		case equalLines(co, cb), equalLines(ca, cb):
+			writeLines(&out, ca)
+		default:
+			conflicts++
+			writeConflict(&out, co, ca, cb, labels)
+		}
+		lo, la, lb = next, na, nb
+	}
+
+	return out.String(), conflicts
+}
+
+// splitLines splits content into lines, keeping the line endings
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// matchedLines maps the index of each line of base to the index of the line
+// it matches in other, from the longest common subsequences of the two
+func matchedLines(base []string, other []string) map[int]int {
+	matched := make(map[int]int)
+	m := difflib.NewMatcherWithJunk(base, other, false, nil)
+	for _, block := range m.GetMatchingBlocks() {
+		for i := 0; i < block.Size; i++ {
+			matched[block.A+i] = block.B + i
+		}
+	}
+	return matched
+}
+
+func equalLines(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeLines(out *strings.Builder, lines []string) {
+	for _, l := range lines {
+		out.WriteString(l)
+	}
+}
+
+func writeConflict(out *strings.Builder, base []string, current []string, other []string, labels mergeLabels) {
+	section := func(marker string, lines []string) {
+		out.WriteString(marker + "\n")
+		writeLines(out, lines)
+		if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+			out.WriteString("\n")
+		}
+	}
+	section("<<<<<<< "+labels.Current, current)
+	section("||||||| "+labels.Base, base)
+	section("=======", other)
+	out.WriteString(">>>>>>> " + labels.Other + "\n")
+}
@@ -0,0 +1,63 @@
+package pct
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_merge3(t *testing.T) {
+	labels := mergeLabels{Current: "current", Base: "base", Other: "other"}
+	tests := []struct {
+		name          string
+		base          string
+		current       string
+		other         string
+		want          string
+		wantConflicts int
+	}{
+		{
+			name:    "takes changes made on one side",
+			base:    "a\nb\nc\nd\ne\n",
+			current: "a\nB\nc\nd\ne\n",
+			other:   "a\nb\nc\nd\nE\nf\n",
+			want:    "a\nB\nc\nd\nE\nf\n",
+		},
+		{
+			name:    "takes identical changes made on both sides once",
+			base:    "a\nb\nc\n",
+			current: "a\nB\nc\n",
+			other:   "a\nB\nc\n",
+			want:    "a\nB\nc\n",
+		},
+		{
+			name:    "takes removals",
+			base:    "a\nb\nc\nd\n",
+			current: "a\nc\nd\n",
+			other:   "a\nb\nc\n",
+			want:    "a\nc\n",
+		},
+		{
+			name:          "marks overlapping changes as conflicts",
+			base:          "a\nb\nc\n",
+			current:       "a\nmine\nc\n",
+			other:         "a\ntheirs\nc\n",
+			want:          "a\n<<<<<<< current\nmine\n||||||| base\nb\n=======\ntheirs\n>>>>>>> other\nc\n",
+			wantConflicts: 1,
+		},
+		{
+			name:          "marks additions to empty content as conflicts",
+			current:       "mine",
+			other:         "theirs\n",
+			want:          "<<<<<<< current\nmine\n||||||| base\n=======\ntheirs\n>>>>>>> other\n",
+			wantConflicts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := merge3(tt.base, tt.current, tt.other, labels)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantConflicts, conflicts)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package pct
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// PuppetContentTemplateMode sets the permissions of the deployed files and
+// directories matching Path, a glob relative to the content directory, to
+// Mode, given in octal, e.g. "0755"
+type PuppetContentTemplateMode struct {
+	Path string `mapstructure:"path" json:"path"`
+	Mode string `mapstructure:"mode" json:"mode"`
+}
+
+// defaultFileMode is used for files that don't come from the template content
+const defaultFileMode os.FileMode = 0644
+
+// contentMode returns the permissions to deploy a content path with: the mode
+// of the last declared mode that matches it, otherwise the permissions of the
+// source file
+func contentMode(rel string, source os.DirEntry, modes []PuppetContentTemplateMode) (os.FileMode, error) {
+	var mode os.FileMode
+	if fi, err := source.Info(); err == nil {
+		mode = fi.Mode().Perm()
+	}
+
+	for _, m := range modes {
+		if !matchesAny(rel, []string{filepath.ToSlash(m.Path)}) {
+			continue
+		}
+		declared, err := parseMode(m.Mode)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid mode for '%s': %s", m.Path, err)
+		}
+		mode = declared
+	}
+	return mode, nil
+}
+
+func parseMode(mode string) (os.FileMode, error) {
+	m, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || m > 0777 {
+		return 0, fmt.Errorf("'%s' is not an octal file mode such as 0644", mode)
+	}
+	return os.FileMode(m), nil
+}
@@ -0,0 +1,107 @@
+package pct
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported on windows")
+	}
+
+	cache := t.TempDir()
+	dir := installTestTemplate(t, cache, "modes", "0.1.0")
+	config := `---
+template:
+  id: modes
+  type: project
+  version: 0.1.0
+  modes:
+    - path: bin/*.rb
+      mode: "0750"
+    - path: secrets
+      mode: "0700"
+`
+	files := map[string]os.FileMode{
+		filepath.Join("content", "bin", "setup.sh.tmpl"): 0755,
+		filepath.Join("content", "bin", "console.rb"):    0644,
+		filepath.Join("content", "secrets", "key.txt"):   0600,
+	}
+	if err := os.WriteFile(filepath.Join(dir, TemplateConfigFileName), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for name, mode := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tmp := t.TempDir()
+	info := DeployInfo{
+		SelectedTemplate: "modes",
+		TemplateCache:    cache,
+		TargetOutputDir:  tmp,
+		TargetName:       "woo",
+	}
+	_, err := Deploy(info)
+	assert.NoError(t, err)
+
+	want := map[string]os.FileMode{
+		filepath.Join("bin", "setup.sh"):    0755,
+		filepath.Join("bin", "console.rb"):  0750,
+		filepath.Join("secrets"):            0700,
+		filepath.Join("secrets", "key.txt"): 0600,
+		"version.txt":                       0644,
+	}
+	for name, mode := range want {
+		fi, err := os.Stat(filepath.Join(tmp, "woo", name))
+		if assert.NoError(t, err) {
+			assert.Equal(t, mode, fi.Mode().Perm(), name)
+		}
+	}
+
+	// losing the executable bit is a change, even with the same content
+	setup := filepath.Join(tmp, "woo", "bin", "setup.sh")
+	assert.NoError(t, os.Chmod(setup, 0644))
+	var out bytes.Buffer
+	changed, err := Diff(info, &out)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "mode change 0644 => 0755 "+setup+"\n", out.String())
+}
+
+func Test_parseMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		want    os.FileMode
+		wantErr bool
+	}{
+		{mode: "0755", want: 0755},
+		{mode: "644", want: 0644},
+		{mode: "0999", wantErr: true},
+		{mode: "01777", wantErr: true},
+		{mode: "rwx", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			got, err := parseMode(tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package pct
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	integerValue = regexp.MustCompile(`^-?\d+$`)
+	floatValue   = regexp.MustCompile(`^-?\d+\.\d+$`)
+)
+
+// ParseOverrides parses template variable overrides given in the form
+// key=value, where key is the dotted path of the variable, for example
+// `puppet_module.license=MIT`. Returns the raw values by their lowercased key,
+// ready to be used as DeployInfo.Overrides
+func ParseOverrides(sets []string) (map[string]string, error) {
+	overrides := make(map[string]string)
+	for _, set := range sets {
+		parts := strings.SplitN(set, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || key == "" {
+			return nil, fmt.Errorf("Invalid override '%s', expected the form key=value", set)
+		}
+		overrides[strings.ToLower(key)] = parts[1]
+	}
+	return overrides, nil
+}
+
+// coerceValue converts the raw value of an override into the type it looks
+// like: true/false become booleans, numbers become ints or floats and
+// [a,b,c] becomes a list. Quoting a value keeps it as a string
+func coerceValue(raw string) interface{} {
+	value := strings.TrimSpace(raw)
+	if len(value) >= 2 {
+		if (value[0] == '"' && value[len(value)-1] == '"') || (value[0] == '\'' && value[len(value)-1] == '\'') {
+			return value[1 : len(value)-1]
+		}
+	}
+
+	switch {
+	case strings.EqualFold(value, "true"):
+		return true
+	case strings.EqualFold(value, "false"):
+		return false
+	case integerValue.MatchString(value):
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	case floatValue.MatchString(value):
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+		list := []interface{}{}
+		for _, item := range strings.Split(value[1:len(value)-1], ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, coerceValue(item))
+			}
+		}
+		return list
+	}
+	return raw
+}
+
+// overrideValue converts the raw value of an override using the type of the
+// matching declared parameter, falling back to coerceValue for undeclared
+// variables. Values that fail to convert are left as given, so they are
+// reported when the parameters are validated
+func overrideValue(key string, raw string, params []PuppetContentTemplateParameter) interface{} {
+	for _, p := range params {
+		if !strings.EqualFold(p.Name, key) {
+			continue
+		}
+		if p.Type == ParameterTypeString || p.Type == ParameterTypeEnum || p.Type == "" {
+			return raw
+		}
+		value, err := parseParameterValue(p, strings.TrimSpace(raw))
+		if err != nil {
+			return raw
+		}
+		return value
+	}
+	return coerceValue(raw)
+}
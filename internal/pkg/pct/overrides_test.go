@@ -0,0 +1,100 @@
+package pct
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOverrides(t *testing.T) {
+	tests := []struct {
+		name    string
+		sets    []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "parses dotted keys",
+			sets: []string{"puppet_module.license=MIT", "Puppet_Module.Summary=A module = great"},
+			want: map[string]string{
+				"puppet_module.license": "MIT",
+				"puppet_module.summary": "A module = great",
+			},
+		},
+		{
+			name: "allows empty values",
+			sets: []string{"puppet_module.summary="},
+			want: map[string]string{"puppet_module.summary": ""},
+		},
+		{
+			name:    "returns an error without a value",
+			sets:    []string{"puppet_module.license"},
+			wantErr: true,
+		},
+		{
+			name:    "returns an error without a key",
+			sets:    []string{"=MIT"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOverrides(tt.sets)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseOverrides() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_coerceValue(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want interface{}
+	}{
+		{raw: "MIT", want: "MIT"},
+		{raw: "true", want: true},
+		{raw: "False", want: false},
+		{raw: "8080", want: 8080},
+		{raw: "-1", want: -1},
+		{raw: "1.5", want: 1.5},
+		{raw: "0.1.0", want: "0.1.0"},
+		{raw: `"8080"`, want: "8080"},
+		{raw: "'true'", want: "true"},
+		{raw: "[RedHat, Ubuntu, 7]", want: []interface{}{"RedHat", "Ubuntu", 7}},
+		{raw: "[]", want: []interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			assert.Equal(t, tt.want, coerceValue(tt.raw))
+		})
+	}
+}
+
+func Test_overrideValue(t *testing.T) {
+	params := []PuppetContentTemplateParameter{
+		{Name: "puppet_module.version", Type: ParameterTypeString},
+		{Name: "puppet_module.port", Type: ParameterTypeInt},
+		{Name: "puppet_module.operatingsystems", Type: ParameterTypeList},
+	}
+	tests := []struct {
+		key  string
+		raw  string
+		want interface{}
+	}{
+		{key: "puppet_module.version", raw: "1", want: "1"},
+		{key: "puppet_module.port", raw: "8080", want: 8080},
+		{key: "puppet_module.port", raw: "eighty", want: "eighty"},
+		{key: "puppet_module.operatingsystems", raw: "RedHat,Ubuntu", want: []interface{}{"RedHat", "Ubuntu"}},
+		{key: "undeclared", raw: "true", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.raw, func(t *testing.T) {
+			assert.Equal(t, tt.want, overrideValue(tt.key, tt.raw, params))
+		})
+	}
+}
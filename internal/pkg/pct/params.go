@@ -0,0 +1,229 @@
+package pct
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/olekukonko/tablewriter"
+	"github.com/rs/zerolog/log"
+)
+
+// Parameter types supported in a template's parameter declarations
+const (
+	ParameterTypeString = "string"
+	ParameterTypeInt    = "int"
+	ParameterTypeBool   = "bool"
+	ParameterTypeEnum   = "enum"
+	ParameterTypeList   = "list"
+)
+
+// PuppetContentTemplateParameter declares a value a template accepts. The Name
+// is the dotted path of the value within the template data, for example
+// `puppet_module.license`
+type PuppetContentTemplateParameter struct {
+	Name        string        `mapstructure:"name" json:"name"`
+	Type        string        `mapstructure:"type" json:"type"`
+	Description string        `mapstructure:"description" json:"description,omitempty"`
+	Required    bool          `mapstructure:"required" json:"required"`
+	Default     interface{}   `mapstructure:"default" json:"default,omitempty"`
+	Pattern     string        `mapstructure:"pattern" json:"pattern,omitempty"`
+	Values      []interface{} `mapstructure:"values" json:"values,omitempty"`
+}
+
+// ParameterError lists every template parameter that failed validation
+type ParameterError struct {
+	Problems []string
+}
+
+func (e *ParameterError) Error() string {
+	return fmt.Sprintf("Invalid template parameters:\n  - %s", strings.Join(e.Problems, "\n  - "))
+}
+
+// DisplayParameters returns the declared parameters of a template in table or
+// json format
+func DisplayParameters(params []PuppetContentTemplateParameter, format string) string {
+	switch format {
+	case "table":
+		if len(params) == 0 {
+			return "This template has no configuration options."
+		}
+
+		var b bytes.Buffer
+		table := tablewriter.NewWriter(&b)
+		table.SetHeader([]string{"Name", "Type", "Required", "Default", "Constraint", "Description"})
+		table.SetBorder(false)
+		table.SetAutoWrapText(false)
+		for _, p := range params {
+			def := ""
+			if p.Default != nil {
+				def = fmt.Sprint(p.Default)
+			}
+			table.Append([]string{p.Name, p.Type, fmt.Sprint(p.Required), def, describeConstraint(p), p.Description})
+		}
+		table.Render()
+		return b.String()
+	case "json":
+		j := jsoniter.ConfigFastest
+		prettyParams, err := j.MarshalIndent(params, "", "  ")
+		if err != nil {
+			log.Error().Msgf("Error converting to json: %v", err)
+		}
+		return string(prettyParams)
+	}
+	return ""
+}
+
+func describeConstraint(p PuppetContentTemplateParameter) string {
+	var c []string
+	if len(p.Values) > 0 {
+		var values []string
+		for _, v := range p.Values {
+			values = append(values, fmt.Sprint(v))
+		}
+		c = append(c, fmt.Sprintf("one of: %s", strings.Join(values, ", ")))
+	}
+	if p.Pattern != "" {
+		c = append(c, fmt.Sprintf("matches: %s", p.Pattern))
+	}
+	return strings.Join(c, "; ")
+}
+
+// validateParameters checks the resolved template data against the parameters
+// declared by the template, returning a ParameterError describing every value
+// that is missing or invalid
+func validateParameters(params []PuppetContentTemplateParameter, config map[string]interface{}) error {
+	var problems []string
+	for _, p := range params {
+		value, ok := lookupValue(config, p.Name)
+		if !ok || value == nil {
+			if p.Required {
+				problems = append(problems, fmt.Sprintf("%s: a value is required", p.Name))
+			}
+			continue
+		}
+
+		if err := validateParameter(p, value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", p.Name, err))
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return &ParameterError{Problems: problems}
+	}
+	return nil
+}
+
+func validateParameter(p PuppetContentTemplateParameter, value interface{}) error {
+	var pattern *regexp.Regexp
+	if p.Pattern != "" {
+		var err error
+		pattern, err = regexp.Compile(p.Pattern)
+		if err != nil {
+			return fmt.Errorf("the template declares an invalid pattern '%s': %s", p.Pattern, err)
+		}
+	}
+	matches := func(v interface{}) error {
+		if pattern != nil && !pattern.MatchString(fmt.Sprint(v)) {
+			return fmt.Errorf("'%v' does not match the pattern '%s'", v, p.Pattern)
+		}
+		return nil
+	}
+
+	switch p.Type {
+	case ParameterTypeString, "":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("'%v' is not a string", value)
+		}
+	case ParameterTypeInt:
+		if !isInteger(value) {
+			return fmt.Errorf("'%v' is not an integer", value)
+		}
+	case ParameterTypeBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("'%v' is not a boolean", value)
+		}
+	case ParameterTypeEnum:
+		found := false
+		for _, v := range p.Values {
+			if fmt.Sprint(v) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("'%v' is not one of: %s", value, describeValues(p.Values))
+		}
+	case ParameterTypeList:
+		list, ok := toList(value)
+		if !ok {
+			return fmt.Errorf("'%v' is not a list", value)
+		}
+		for _, v := range list {
+			if err := matches(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("the template declares an unknown type '%s'", p.Type)
+	}
+
+	return matches(value)
+}
+
+func describeValues(values []interface{}) string {
+	var s []string
+	for _, v := range values {
+		s = append(s, fmt.Sprint(v))
+	}
+	return strings.Join(s, ", ")
+}
+
+func isInteger(value interface{}) bool {
+	switch v := value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	case float32:
+		return float64(v) == math.Trunc(float64(v))
+	case float64:
+		return v == math.Trunc(v)
+	}
+	return false
+}
+
+func toList(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, true
+	case []string:
+		list := make([]interface{}, len(v))
+		for i, s := range v {
+			list[i] = s
+		}
+		return list, true
+	}
+	return nil, false
+}
+
+// lookupValue finds a value in nested template data using a dotted key. Keys
+// are matched case insensitively, as the template data is loaded by viper
+func lookupValue(config map[string]interface{}, key string) (interface{}, bool) {
+	var current interface{} = config
+	for _, part := range strings.Split(strings.ToLower(key), ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
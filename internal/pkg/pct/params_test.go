@@ -0,0 +1,149 @@
+package pct
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validateParameters(t *testing.T) {
+	params := []PuppetContentTemplateParameter{
+		{Name: "puppet_module.summary", Type: ParameterTypeString, Required: true},
+		{Name: "puppet_module.version", Type: ParameterTypeString, Pattern: `^\d+\.\d+\.\d+$`},
+		{Name: "puppet_module.license", Type: ParameterTypeEnum, Values: []interface{}{"Apache-2.0", "MIT"}},
+		{Name: "puppet_module.port", Type: ParameterTypeInt},
+		{Name: "puppet_module.managed", Type: ParameterTypeBool},
+		{Name: "puppet_module.operatingsystems", Type: ParameterTypeList, Pattern: `^[A-Z]`},
+	}
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		want   []string
+	}{
+		{
+			name: "accepts valid values",
+			config: map[string]interface{}{
+				"puppet_module": map[string]interface{}{
+					"summary":          "A module",
+					"version":          "0.1.0",
+					"license":          "MIT",
+					"port":             8080,
+					"managed":          true,
+					"operatingsystems": []interface{}{"RedHat", "Ubuntu"},
+				},
+			},
+		},
+		{
+			name: "accepts missing optional values",
+			config: map[string]interface{}{
+				"puppet_module": map[string]interface{}{
+					"summary": "A module",
+				},
+			},
+		},
+		{
+			name: "accepts integral floats as integers",
+			config: map[string]interface{}{
+				"puppet_module": map[string]interface{}{
+					"summary": "A module",
+					"port":    float64(8080),
+				},
+			},
+		},
+		{
+			name:   "rejects a missing required value",
+			config: map[string]interface{}{},
+			want:   []string{"puppet_module.summary: a value is required"},
+		},
+		{
+			name: "rejects every invalid value",
+			config: map[string]interface{}{
+				"puppet_module": map[string]interface{}{
+					"summary":          42,
+					"version":          "one",
+					"license":          "GPL",
+					"port":             "eighty",
+					"managed":          "yes",
+					"operatingsystems": []interface{}{"RedHat", "ubuntu"},
+				},
+			},
+			want: []string{
+				"puppet_module.license: 'GPL' is not one of: Apache-2.0, MIT",
+				"puppet_module.managed: 'yes' is not a boolean",
+				"puppet_module.operatingsystems: 'ubuntu' does not match the pattern '^[A-Z]'",
+				"puppet_module.port: 'eighty' is not an integer",
+				"puppet_module.summary: '42' is not a string",
+				`puppet_module.version: 'one' does not match the pattern '^\d+\.\d+\.\d+$'`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateParameters(params, tt.config)
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			if assert.IsType(t, &ParameterError{}, err) {
+				assert.Equal(t, tt.want, err.(*ParameterError).Problems)
+			}
+		})
+	}
+}
+
+func TestDisplayParameters(t *testing.T) {
+	info, err := GetInfo("testdata/examples", "param-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		params []PuppetContentTemplateParameter
+		format string
+		want   []string
+	}{
+		{
+			name:   "table example",
+			params: info.Template.Parameters,
+			format: "table",
+			want: []string{
+				"NAME", "TYPE", "REQUIRED", "DEFAULT", "CONSTRAINT", "DESCRIPTION",
+				"puppet_module.license", "enum", "Apache-2.0", "one of: Apache-2.0, MIT",
+				`matches: ^\d+\.\d+\.\d+$`,
+				"[RedHat Ubuntu]",
+			},
+		},
+		{
+			name:   "empty table example",
+			format: "table",
+			want:   []string{"This template has no configuration options."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DisplayParameters(tt.params, tt.format)
+			for _, w := range tt.want {
+				assert.Contains(t, got, w)
+			}
+		})
+	}
+
+	t.Run("json example", func(t *testing.T) {
+		var got []map[string]interface{}
+		err := json.Unmarshal([]byte(DisplayParameters(info.Template.Parameters, "json")), &got)
+		assert.NoError(t, err)
+		assert.Len(t, got, 4)
+		assert.Equal(t, map[string]interface{}{
+			"name":        "puppet_module.license",
+			"type":        "enum",
+			"description": "The license the module is released under",
+			"required":    false,
+			"default":     "Apache-2.0",
+			"values":      []interface{}{"Apache-2.0", "MIT"},
+		}, got[1])
+	})
+}
@@ -0,0 +1,38 @@
+package pct
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PartialsDirName is the directory of a template holding partials, shared
+// snippets that content files can render with `{{template "name" .}}`
+const PartialsDirName = "partials"
+
+// collectPartials finds the partials of a set of templates, keyed by their
+// path within the partials directory without the .tmpl extension, e.g.
+// `license_header` for `partials/license_header.tmpl`. A partial of a later
+// template replaces one of the same name from an earlier template
+func collectPartials(layers []templateEntry) (map[string]string, error) {
+	partials := make(map[string]string)
+	for _, layer := range layers {
+		dir := filepath.Join(layer.Path, PartialsDirName)
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			continue
+		}
+
+		err := filepath.WalkDir(dir, func(path string, info os.DirEntry, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			rel, _ := filepath.Rel(dir, path)
+			partials[strings.TrimSuffix(filepath.ToSlash(rel), ".tmpl")] = path
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return partials, nil
+}
@@ -0,0 +1,63 @@
+package pct
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writePartials writes partial files into the partials directory of an
+// installed test template
+func writePartials(t *testing.T, templatePath string, id string, version string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(templatePath, id, version, PartialsDirName, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDeployPartials(t *testing.T) {
+	cache := t.TempDir()
+	installLayeredTemplate(t, cache, "base", "0.1.0", "", "license: Apache-2.0\n", nil)
+	writePartials(t, cache, "base", "0.1.0", map[string]string{
+		"license_header.tmpl":   "# Licensed under {{ .license }}",
+		"readme/usage.tmpl":     "base usage",
+		"readme/unchanged.tmpl": "base unchanged",
+	})
+	installLayeredTemplate(t, cache, "company", "0.1.0", "  includes:\n    - id: base\n", "", map[string]string{
+		"init.pp.tmpl":   "{{template \"license_header\" .}}\nclass {{ .pct_name }} {}",
+		"README.md.tmpl": "{{template \"readme/usage\" .}}, {{template \"readme/unchanged\" .}}",
+	})
+	writePartials(t, cache, "company", "0.1.0", map[string]string{
+		"readme/usage.tmpl": "company usage",
+	})
+
+	tmp := t.TempDir()
+	_, err := Deploy(DeployInfo{
+		SelectedTemplate: "company",
+		TemplateCache:    cache,
+		TargetOutputDir:  tmp,
+		TargetName:       "woo",
+	})
+	assert.NoError(t, err)
+
+	want := map[string]string{
+		"init.pp":   "# Licensed under Apache-2.0\nclass woo {}",
+		"README.md": "company usage, base unchanged",
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(tmp, "woo", name))
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(got), name)
+	}
+
+	// partials are not deployed as content
+	_, err = os.Stat(filepath.Join(tmp, "woo", PartialsDirName))
+	assert.True(t, os.IsNotExist(err))
+}
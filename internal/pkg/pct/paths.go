@@ -0,0 +1,90 @@
+package pct
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// actionPattern matches each action in a path segment, and actionTokenPattern
+// the string literals, numbers and identifiers within an action
+var (
+	actionPattern      = regexp.MustCompile(`\{\{.*?\}\}`)
+	actionTokenPattern = regexp.MustCompile(`"(?:\\.|[^"\\])*"|` + "`[^`]*`" + `|\d[\w.]*|[.$]?[A-Za-z_][\w.]*`)
+)
+
+// renderPath renders each segment of a path relative to the content directory
+// as a template, with the same data and functions as file contents, and drops
+// the .tmpl extension. Variables can be referenced with or without a leading
+// dot, e.g. `{{pct_name}}` or `{{.pct_name}}`. An empty string is returned
+// when any segment renders as empty, so the file or directory is skipped
+func renderPath(rel string, config map[string]interface{}, strict bool) (string, error) {
+	var rendered []string
+	for _, segment := range strings.Split(filepath.ToSlash(rel), "/") {
+		segment = strings.TrimSuffix(segment, ".tmpl")
+		if strings.Contains(segment, "{{") {
+			var err error
+			segment, err = renderSegment(rel, segment, config, strict)
+			if err != nil {
+				return "", err
+			}
+			if strings.TrimSpace(segment) == "" || segment == "<no value>" {
+				return "", nil
+			}
+		}
+		rendered = append(rendered, segment)
+	}
+	return filepath.Join(rendered...), nil
+}
+
+func renderSegment(rel string, segment string, config map[string]interface{}, strict bool) (string, error) {
+	funcs := templateFuncs()
+	segment = actionPattern.ReplaceAllStringFunc(segment, func(action string) string {
+		return actionTokenPattern.ReplaceAllStringFunc(action, func(token string) string {
+			name := strings.SplitN(token, ".", 2)[0]
+			if _, isFunc := funcs[name]; isFunc {
+				return token
+			}
+			if _, isVar := config[name]; !isVar {
+				return token
+			}
+			return "." + token
+		})
+	})
+
+	tmpl, err := template.New(filepath.ToSlash(rel)).Funcs(funcs).Parse(segment)
+	if err != nil {
+		return "", err
+	}
+	if strict {
+		tmpl.Option("missingkey=error")
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, config); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// resolveTargetPath renders a path relative to the content directory and
+// joins it to the output directory, checking the result stays within it
+func resolveTargetPath(outputDir string, rel string, config map[string]interface{}, strict bool) (string, error) {
+	if rel == "." {
+		return outputDir, nil
+	}
+
+	target, err := renderPath(rel, config, strict)
+	if err != nil || target == "" {
+		return "", err
+	}
+
+	targetFile := filepath.Join(outputDir, target)
+	if targetFile == filepath.Clean(outputDir) || !isWithinDirectory(outputDir, targetFile) {
+		return "", fmt.Errorf("the path renders as '%s', which is outside the output directory", target)
+	}
+	return targetFile, nil
+}
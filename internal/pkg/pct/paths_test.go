@@ -0,0 +1,90 @@
+package pct
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_resolveTargetPath(t *testing.T) {
+	config := map[string]interface{}{
+		"pct_name": "MyModule",
+		"puppet_module": map[string]interface{}{
+			"class": "apache",
+		},
+		"ci": map[string]interface{}{
+			"provider": "gitlab",
+		},
+		"escape": "../..",
+	}
+	out := filepath.Join("tmp", "out")
+
+	tests := []struct {
+		rel     string
+		strict  bool
+		want    string
+		wantErr bool
+	}{
+		{rel: ".", want: out},
+		{rel: "README.md.tmpl", want: filepath.Join(out, "README.md")},
+		{rel: "{{pct_name}}.txt.tmpl", want: filepath.Join(out, "MyModule.txt")},
+		{rel: "manifests/{{puppet_module.class}}.pp", want: filepath.Join(out, "manifests", "apache.pp")},
+		{rel: "manifests/{{ .puppet_module.class }}.pp", want: filepath.Join(out, "manifests", "apache.pp")},
+		{rel: "spec/classes/{{pct_name | snake}}_spec.rb.tmpl", want: filepath.Join(out, "spec", "classes", "my_module_spec.rb")},
+		{rel: `{{ if eq ci.provider "gitlab" }}.gitlab-ci.yml{{ end }}`, want: filepath.Join(out, ".gitlab-ci.yml")},
+		{rel: `{{ if eq ci.provider "github" }}.github{{ end }}/ci.yml`, want: ""},
+		{rel: `{{ if eq ci.provider "ci" }}ci{{ end }}`, want: ""},
+		{rel: "{{ .missing }}.txt", want: filepath.Join(out, "<no value>.txt")},
+		{rel: "{{ .missing }}", want: ""},
+		{rel: "{{ .missing }}.txt", strict: true, wantErr: true},
+		{rel: "{{ escape }}/etc/passwd", wantErr: true},
+		{rel: "{{ .pct_name", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rel, func(t *testing.T) {
+			got, err := resolveTargetPath(out, filepath.FromSlash(tt.rel), config, tt.strict)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveTargetPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDeployTemplatedPaths(t *testing.T) {
+	cache := t.TempDir()
+	dir := installTestTemplate(t, cache, "paths", "0.1.0")
+	path := filepath.Join(dir, "content", "manifests", "{{puppet_module.class}}.pp.tmpl")
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("class {{.puppet_module.class}} {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tmp := t.TempDir()
+	_, err := Deploy(DeployInfo{
+		SelectedTemplate: "paths",
+		TemplateCache:    cache,
+		TargetOutputDir:  tmp,
+		TargetName:       "woo",
+		Overrides:        map[string]string{"puppet_module.class": "apache"},
+	})
+	assert.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(tmp, "woo", "manifests", "apache.pp"))
+	assert.NoError(t, err)
+	assert.Equal(t, "class apache {}\n", string(content))
+
+	_, err = Deploy(DeployInfo{
+		SelectedTemplate: "paths",
+		TemplateCache:    cache,
+		TargetOutputDir:  t.TempDir(),
+		TargetName:       "woo",
+		Overrides:        map[string]string{"puppet_module.class": "../../../escaped"},
+	})
+	if assert.IsType(t, &DeployError{}, err) {
+		assert.Contains(t, err.(*DeployError).Files[0].Message, "outside the output directory")
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -47,6 +48,26 @@ type PuppetContentTemplate struct {
 	Display string `mapstructure:"display"`
 	Version string `mapstructure:"version"`
 	URL     string `mapstructure:"url"`
+	// Parameters declares the values the template accepts, along with their
+	// types, defaults and validation
+	Parameters []PuppetContentTemplateParameter `mapstructure:"parameters" json:"Parameters,omitempty"`
+	// Strict fails rendering on any reference to an undefined variable
+	Strict bool `mapstructure:"strict" json:"Strict,omitempty"`
+	// Rules include parts of the content only when a condition is true
+	Rules []PuppetContentTemplateRule `mapstructure:"rules" json:"Rules,omitempty"`
+	// CopyOnly lists globs, relative to the content directory, of .tmpl files
+	// that are copied as they are rather than rendered
+	CopyOnly []string `mapstructure:"copy_only" json:"CopyOnly,omitempty"`
+	// Modes sets the permissions of deployed paths, overriding the
+	// permissions of the source files
+	Modes []PuppetContentTemplateMode `mapstructure:"modes" json:"Modes,omitempty"`
+	// Includes lists templates deployed, in order, before this one
+	Includes []PuppetContentTemplateInclude `mapstructure:"includes" json:"Includes,omitempty"`
+	// Hooks are commands run, in order, after the template has been deployed
+	Hooks []PuppetContentTemplateHook `mapstructure:"hooks" json:"Hooks,omitempty"`
+	// Default is set when listing templates to mark the version of a template
+	// that is used when no version is selected
+	Default bool `mapstructure:"-"`
 }
 
 // PuppetContentTemplateFileInfo represents the resolved target path information
@@ -57,6 +78,10 @@ type PuppetContentTemplateFileInfo struct {
 	TargetDir      string
 	TargetFile     string
 	IsDirectory    bool
+	// IsCopy marks a file that is copied byte for byte rather than rendered
+	IsCopy bool
+	// Mode is the permissions of the deployed file or directory
+	Mode os.FileMode
 }
 
 // PDKInfo contains the current version information of the compiled binary for
@@ -74,6 +99,36 @@ type DeployInfo struct {
 	TargetOutputDir  string
 	TargetName       string
 	PdkInfo          PDKInfo
+	// Answers are values provided by the user for this deployment, from an
+	// answers file or prompts, which override the template defaults and the
+	// user's pct.yml
+	Answers map[string]interface{}
+	// Overrides are raw values by dotted key, as given by `pct new --set`,
+	// which take precedence over every other source of template data
+	Overrides map[string]string
+	// WriteAnswers writes the resolved template data to AnswersFileName in
+	// the output directory, so the deployment can be replayed later
+	WriteAnswers bool
+	// DryRun resolves and renders every file without writing anything
+	DryRun bool
+	// Strict fails the deployment on any reference to an undefined variable,
+	// as does setting `strict` in the template's pct-config.yml
+	Strict bool
+	// OnConflict is the policy for existing files that differ from the
+	// template output, one of ConflictPolicies. Defaults to ConflictFail
+	OnConflict string
+	// NoHooks skips the hooks declared by the template
+	NoHooks bool
+	// TrustHooks runs the hooks declared by the template without asking for
+	// confirmation, as does listing the template's id in TrustedTemplates
+	TrustHooks       bool
+	TrustedTemplates []string
+	// In and Out are used to confirm and run hooks. Hooks of an untrusted
+	// template are skipped when In is nil, and the prompt and their output go
+	// to stderr when Out is nil, keeping stdout for the deployment result. When In is also given to PromptParameters it should be
+	// the same *bufio.Reader, so input read ahead by the prompts isn't lost
+	In  io.Reader
+	Out io.Writer
 }
 
 type osWrapper interface {
@@ -88,43 +143,98 @@ func (osf osFunc) Getwd() (dir string, err error) {
 
 var osUtils osWrapper = osFunc{}
 
+// Get returns the template matching selectedTemplate, which is either a template
+// id or an <id>@<version> reference. When no version is given the highest
+// installed version is returned
 func Get(templateCache string, selectedTemplate string) (PuppetContentTemplate, error) {
 	info, err := GetInfo(templateCache, selectedTemplate)
 	return info.Template, err
 }
 
+// GetInfo returns the configuration of the template matching selectedTemplate
 func GetInfo(templateCache string, selectedTemplate string) (PuppetContentTemplateInfo, error) {
-	file := filepath.Join(templateCache, selectedTemplate, TemplateConfigFileName)
-	_, err := os.Stat(file)
-	if os.IsNotExist(err) {
-		return PuppetContentTemplateInfo{}, fmt.Errorf("Couldn't find an installed template that matches '%s'", selectedTemplate)
+	entry, err := findTemplate(templateCache, selectedTemplate)
+	if err != nil {
+		return PuppetContentTemplateInfo{}, err
 	}
-	i := readTemplateConfig(file)
-	return i, nil
+	return entry.Info, nil
 }
 
 // List lists all templates in a given path and parses their configuration. Does
 // not return any errors from parsing invalid templates, but returns them as
-// debug log events
+// debug log events. Every installed version of a template is listed, newest
+// first, with the version used by default marked
 func List(templatePath string, templateName string) ([]PuppetContentTemplate, error) {
-	log.Debug().Msgf("Searching %+v for templates", templatePath)
-	matches, _ := filepath.Glob(templatePath + "/**/" + TemplateConfigFileName)
-
 	var tmpls []PuppetContentTemplate
-	for _, file := range matches {
-		log.Debug().Msgf("Found: %+v", file)
-		i := readTemplateConfig(file).Template
-		tmpls = append(tmpls, i)
+	seen := make(map[string]bool)
+	for _, entry := range discover(templatePath) {
+		tmpl := entry.Info.Template
+		tmpl.Default = !seen[tmpl.Id]
+		seen[tmpl.Id] = true
+		tmpls = append(tmpls, tmpl)
 	}
 
 	if templateName != "" {
 		log.Debug().Msgf("Filtering for: %s", templateName)
-		tmpls = filterFiles(tmpls, func(f PuppetContentTemplate) bool { return f.Id == templateName })
+		id, version := ParseTemplateReference(templateName)
+		tmpls = filterFiles(tmpls, func(f PuppetContentTemplate) bool {
+			return f.Id == id && (version == "" || f.Version == version)
+		})
 	}
 
 	return tmpls, nil
 }
 
+// templateEntry is a template found within a template cache along with the
+// directory it is installed in
+type templateEntry struct {
+	Path string
+	Info PuppetContentTemplateInfo
+}
+
+// discover finds every template in a template cache, ordered by id and then
+// from the newest to the oldest version. Templates can either sit directly
+// beneath the cache (<cache>/<id>/pct-config.yml) or be keyed by id and
+// version as laid out by Install (<cache>/<id>/<version>/pct-config.yml)
+func discover(templatePath string) []templateEntry {
+	log.Debug().Msgf("Searching %+v for templates", templatePath)
+	matches, _ := filepath.Glob(filepath.Join(templatePath, "*", TemplateConfigFileName))
+	versioned, _ := filepath.Glob(filepath.Join(templatePath, "*", "*", TemplateConfigFileName))
+	matches = append(matches, versioned...)
+
+	var entries []templateEntry
+	for _, file := range matches {
+		log.Debug().Msgf("Found: %+v", file)
+		entries = append(entries, templateEntry{
+			Path: filepath.Dir(file),
+			Info: readTemplateConfig(file),
+		})
+	}
+
+	sortByVersion(entries)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Info.Template.Id < entries[j].Info.Template.Id
+	})
+
+	return entries
+}
+
+// findTemplate returns the installed template matching a template reference.
+// A reference is either the template id, which selects the highest installed
+// version, or <id>@<version> to select a specific version
+func findTemplate(templatePath string, ref string) (templateEntry, error) {
+	id, version := ParseTemplateReference(ref)
+	for _, entry := range discover(templatePath) {
+		if entry.Info.Template.Id != id {
+			continue
+		}
+		if version == "" || entry.Info.Template.Version == version {
+			return entry, nil
+		}
+	}
+	return templateEntry{}, fmt.Errorf("Couldn't find an installed template that matches '%s'", ref)
+}
+
 // FormatTemplates formats one or more templates to display on the console in
 // table format or json format.
 func FormatTemplates(tmpls []PuppetContentTemplate, jsonOutput string) error {
@@ -142,11 +252,20 @@ func FormatTemplates(tmpls []PuppetContentTemplate, jsonOutput string) error {
 			fmt.Printf("TemplateURL:     %v\n", tmpls[0].URL)
 			fmt.Printf("TemplateVersion: %v\n", tmpls[0].Version)
 		} else {
+			versions := make(map[string]int)
+			for _, v := range tmpls {
+				versions[v.Id]++
+			}
+
 			table := tablewriter.NewWriter(os.Stdout)
-			table.SetHeader([]string{"DisplayName", "Name", "Type"})
+			table.SetHeader([]string{"DisplayName", "Name", "Version", "Type"})
 			table.SetBorder(false)
 			for _, v := range tmpls {
-				table.Append([]string{v.Display, v.Id, v.Type})
+				version := v.Version
+				if v.Default && versions[v.Id] > 1 {
+					version += " (default)"
+				}
+				table.Append([]string{v.Display, v.Id, version, v.Type})
 			}
 			table.Render()
 		}
@@ -188,16 +307,41 @@ func DisplayDefaults(defaults map[string]interface{}, format string) string {
 }
 
 // FormatDeployment formats the files returned by the Deploy method to display
-// on the console in table format or json format.
-func FormatDeployment(deployed []string, jsonOutput string) error {
+// on the console in table format or json format. For a dry run the table lists
+// the action that would be taken for each path.
+func FormatDeployment(deployed DeployResult, jsonOutput string) error {
 	switch jsonOutput {
 	case "table":
-		for _, d := range deployed {
-			log.Info().Msgf("Deployed: %v", d)
+		if deployed.DryRun {
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Action", "Path"})
+			table.SetBorder(false)
+			for _, d := range deployed.Files {
+				table.Append([]string{d.Action, d.Path})
+			}
+			table.Render()
+			return nil
+		}
+		for _, d := range deployed.Files {
+			switch d.Action {
+			case ActionSkip:
+				log.Info().Msgf("Skipped: %v", d.Path)
+			case ActionBackup:
+				log.Info().Msgf("Deployed: %v (backed up to %v)", d.Path, d.Backup)
+			default:
+				if d.Copied {
+					log.Info().Msgf("Copied: %v", d.Path)
+				} else {
+					log.Info().Msgf("Deployed: %v", d.Path)
+				}
+			}
+		}
+		for _, h := range deployed.Hooks {
+			log.Info().Msgf("Ran: %v", h)
 		}
 	case "json":
 		j := jsoniter.ConfigFastest
-		prettyJSON, _ := j.MarshalIndent(deployed, "", "  ")
+		prettyJSON, _ := j.MarshalIndent(deployed.Files, "", "  ")
 		fmt.Printf("%s\n", prettyJSON)
 	}
 	return nil
@@ -205,23 +349,62 @@ func FormatDeployment(deployed []string, jsonOutput string) error {
 
 // Deploy deploys a selected template to a target path with a target name using
 // data from both the configuration inside the template and provided by the
-// User in their user config file
-func Deploy(info DeployInfo) []string {
+// User in their user config file. The resolved data is validated against the
+// parameters declared by the template before any files are written. When
+// DryRun is set every file is resolved and rendered but nothing is written.
+// Existing files that differ from the output are handled according to
+// OnConflict, with every conflict reported before anything is written.
+// The output is staged before it is moved into place, and any failure rolls
+// back every change, so the target is either fully deployed or left untouched.
+// Once deployed, the hooks of the template are run. A failed hook is returned
+// as an error alongside the result, as the files have already been written
+func Deploy(info DeployInfo) (DeployResult, error) {
+	plan, err := planDeployment(info)
+	if _, conflict := err.(*ConflictError); err != nil && !(conflict && info.DryRun) {
+		return DeployResult{}, err
+	}
 
-	log.Trace().Msgf("PDKInfo: %+v", info.PdkInfo)
+	result := DeployResult{DryRun: info.DryRun}
+	if info.DryRun {
+		for _, f := range plan.Files {
+			result.Files = append(result.Files, f.deployed())
+		}
+		return result, nil
+	}
 
-	file := filepath.Join(info.TemplateCache, info.SelectedTemplate, TemplateConfigFileName)
-	log.Debug().Msgf("Template: %s", file)
-	tmpl := readTemplateConfig(file)
-	log.Trace().Msgf("Parsed: %+v", tmpl)
+	tx, err := stageDeployment(plan)
+	if err != nil {
+		return DeployResult{}, err
+	}
+	for _, f := range plan.Files {
+		log.Debug().Msgf("Deploying: %s", f.TargetFilePath)
+		err := tx.apply(f)
+		if err != nil {
+			tx.rollback()
+			return DeployResult{}, fmt.Errorf("Failed to deploy %s, no changes were made: %s", f.TargetFilePath, err)
+		}
+		result.Files = append(result.Files, f.deployed())
+	}
+	if err := tx.apply(plan.Manifest); err != nil {
+		tx.rollback()
+		return DeployResult{}, fmt.Errorf("Failed to deploy %s, no changes were made: %s", plan.Manifest.TargetFilePath, err)
+	}
+	tx.cleanup()
 
+	result.Hooks, err = deployHooks(info, plan.Hooks)
+	return result, err
+}
+
+// resolveTarget fills in the target name and output directory of a deployment
+// when they have not been given, based on the current working directory
+func resolveTarget(info DeployInfo, tmpl PuppetContentTemplate) DeployInfo {
 	if info.TargetName == "" && info.TargetOutputDir == "" { // pdk new foo-foo
 		cwd, _ := osUtils.Getwd()
 		info.TargetName = filepath.Base(cwd)
 		info.TargetOutputDir = cwd
 	} else if info.TargetName != "" && info.TargetOutputDir == "" { // pdk new foo-foo -n wakka
 		cwd, _ := osUtils.Getwd()
-		if tmpl.Template.Type == "project" {
+		if tmpl.Type == "project" {
 			info.TargetOutputDir = filepath.Join(cwd, info.TargetName)
 		} else {
 			info.TargetOutputDir = cwd
@@ -229,98 +412,16 @@ func Deploy(info DeployInfo) []string {
 	} else if info.TargetName == "" && info.TargetOutputDir != "" { // pdk new foo-foo -o /foo/bar/baz
 		info.TargetName = filepath.Base(info.TargetOutputDir)
 	} else if info.TargetName != "" && info.TargetOutputDir != "" { // pdk new foo-foo -n wakka -o /foo/bar/baz
-		if tmpl.Template.Type == "project" {
+		if tmpl.Type == "project" {
 			info.TargetOutputDir = filepath.Join(info.TargetOutputDir, info.TargetName)
 		}
 	}
-
-	contentDir := filepath.Join(info.TemplateCache, info.SelectedTemplate, "content")
-	log.Debug().Msgf("Target Name: %s", info.TargetName)
-	log.Debug().Msgf("Target Output: %s", info.TargetOutputDir)
-
-	replacer := strings.NewReplacer(
-		contentDir, info.TargetOutputDir,
-		"{{pct_name}}", info.TargetName,
-		".tmpl", "",
-	)
-
-	var templateFiles []PuppetContentTemplateFileInfo
-	err := filepath.WalkDir(contentDir, func(path string, info os.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		log.Trace().Msgf("Processing: %s", path)
-		targetFile := replacer.Replace(path)
-		log.Debug().Msgf("Resolved '%s' to '%s'", path, targetFile)
-
-		dir, file := filepath.Split(targetFile)
-		i := PuppetContentTemplateFileInfo{
-			TemplatePath:   path,
-			TargetFilePath: targetFile,
-			TargetDir:      dir,
-			TargetFile:     file,
-			IsDirectory:    info.IsDir(),
-		}
-		log.Trace().Msgf("Processed: %+v", i)
-
-		templateFiles = append(templateFiles, i)
-		return nil
-	})
-	if err != nil {
-		log.Error().AnErr("content", err)
-	}
-
-	var deployed []string
-	for _, templateFile := range templateFiles {
-		log.Debug().Msgf("Deploying: %s", templateFile.TargetFilePath)
-		if templateFile.IsDirectory {
-			err := createTemplateDirectory(templateFile.TargetFilePath)
-			if err == nil {
-				deployed = append(deployed, templateFile.TargetFilePath)
-			}
-		} else {
-			err := createTemplateFile(info.TargetName, file, templateFile, tmpl.Template, info.PdkInfo)
-			if err != nil {
-				log.Error().Msgf("%s", err)
-				continue
-			}
-			deployed = append(deployed, templateFile.TargetFilePath)
-		}
-	}
-
-	return deployed
+	return info
 }
 
-func createTemplateDirectory(targetDir string) error {
-	log.Trace().Msgf("Creating: '%s'", targetDir)
-	err := os.MkdirAll(targetDir, os.ModePerm)
-
-	if err != nil {
-		log.Error().Msgf("Error: %v", err)
-		return err
-	}
-
-	return nil
-}
-
-func createTemplateFile(targetName string, configFile string, templateFile PuppetContentTemplateFileInfo, tmpl PuppetContentTemplate, pdkInfo PDKInfo) error {
-	log.Trace().Msgf("Creating: '%s'", templateFile.TargetFilePath)
-	config := processConfiguration(
-		targetName,
-		configFile,
-		templateFile.TemplatePath,
-		tmpl,
-		pdkInfo,
-	)
-
-	text, err := renderFile(templateFile.TemplatePath, config)
-	if err != nil {
-		return fmt.Errorf("Failed to create %s", templateFile.TargetFilePath)
-	}
-
-	log.Trace().Msgf("Writing: '%s' '%s'", templateFile.TargetFilePath, text)
-	err = os.MkdirAll(templateFile.TargetDir, os.ModePerm)
+func createTemplateFile(templateFile deployFile) error {
+	log.Trace().Msgf("Writing: '%s' '%s'", templateFile.TargetFilePath, templateFile.Content)
+	err := os.MkdirAll(templateFile.TargetDir, os.ModePerm)
 	if err != nil {
 		log.Error().Msgf("Error: %v", err)
 		return err
@@ -333,7 +434,7 @@ func createTemplateFile(targetName string, configFile string, templateFile Puppe
 	}
 	defer file.Close()
 
-	_, err = io.WriteString(file, text)
+	_, err = io.WriteString(file, templateFile.Content)
 	if err != nil {
 		log.Error().Msgf("Error: %v", err)
 		return err
@@ -345,12 +446,31 @@ func createTemplateFile(targetName string, configFile string, templateFile Puppe
 		return err
 	}
 
+	if templateFile.Mode != 0 {
+		err = os.Chmod(templateFile.TargetFilePath, templateFile.Mode)
+		if err != nil {
+			log.Error().Msgf("Error: %v", err)
+			return err
+		}
+	}
+
 	return nil
 }
 
-func processConfiguration(projectName string, configFile string, projectTemplate string, tmpl PuppetContentTemplate, pdkInfo PDKInfo) map[string]interface{} {
+func processConfiguration(info DeployInfo, configFile string, tmpl PuppetContentTemplate) map[string]interface{} {
+	return processLayeredConfiguration(info, []templateEntry{
+		{Path: filepath.Dir(configFile), Info: PuppetContentTemplateInfo{Template: tmpl}},
+	})
+}
+
+// processLayeredConfiguration resolves the template data for a template and
+// the templates it includes, given in order, with the defaults and parameters
+// of later templates taking precedence over earlier ones
+func processLayeredConfiguration(info DeployInfo, layers []templateEntry) map[string]interface{} {
 	v := viper.New()
+	params := layeredParameters(layers)
 
+	pdkInfo := info.PdkInfo
 	log.Trace().Msgf("PDKInfo: %+v", pdkInfo)
 	/*
 		Inheritance (each level overwritten by next):
@@ -359,16 +479,26 @@ func processConfiguration(projectName string, configFile string, projectTemplate
 			machine variables
 				- information that comes from the current machine
 				- user name, hostname, etc
+			parameter defaults
+				- the `default` of each parameter declared by the template
 			template variables
 				- information from the template itself
 				- designed to be runnable defaults for everything inside template
+				- included templates first, then the template itself
 			user overrides
 				- ~/.pdk/pdk.yml
 				- user customizations for their preferences
+			answers
+				- values given for this deployment by `pct new --answers <file>`
+				  and `pct new --interactive`
+			command line overrides
+				- values given by `pct new --set key=value`
+				- converted to the type of the declared parameter, or the type
+				  they look like
 	*/
 
 	// Convention based variables
-	v.SetDefault("pct_name", projectName)
+	v.SetDefault("pct_name", info.TargetName)
 
 	user := getCurrentUser()
 	v.SetDefault("user", user)
@@ -385,15 +515,28 @@ func processConfiguration(projectName string, configFile string, projectTemplate
 	v.SetDefault("pdk.commit_hash", pdkInfo.Commit)
 	v.SetDefault("pdk.build_date", pdkInfo.BuildDate)
 
+	// Defaults of declared template parameters
+	for _, p := range params {
+		if p.Default != nil {
+			v.SetDefault(p.Name, p.Default)
+		}
+	}
+
 	// Template specific variables
-	log.Trace().Msgf("Adding %v", filepath.Dir(configFile))
-	v.SetConfigName(TemplateConfigName)
-	v.SetConfigType("yml")
-	v.AddConfigPath(filepath.Dir(configFile))
-	if err := v.ReadInConfig(); err == nil {
-		log.Trace().Msgf("Merging config file: %v", v.ConfigFileUsed())
-	} else {
-		log.Error().Msgf("Error reading config: %v", err)
+	for _, layer := range layers {
+		configFile := filepath.Join(layer.Path, TemplateConfigFileName)
+		log.Trace().Msgf("Adding %v", configFile)
+		lv := viper.New()
+		lv.SetConfigFile(configFile)
+		lv.SetConfigType("yml")
+		if err := lv.ReadInConfig(); err != nil {
+			log.Error().Msgf("Error reading config: %v", err)
+			continue
+		}
+		log.Trace().Msgf("Merging config file: %v", configFile)
+		if err := v.MergeConfigMap(lv.AllSettings()); err != nil {
+			log.Error().Msgf("Error merging config: %v", err)
+		}
 	}
 
 	// User specified variable overrides
@@ -409,6 +552,21 @@ func processConfiguration(projectName string, configFile string, projectTemplate
 		log.Debug().Msgf("Error reading config: %v", err)
 	}
 
+	// Answers given for this deployment
+	if len(info.Answers) > 0 {
+		log.Trace().Msgf("Merging answers: %+v", info.Answers)
+		if err := v.MergeConfigMap(info.Answers); err != nil {
+			log.Error().Msgf("Error merging answers: %v", err)
+		}
+	}
+
+	// Command line overrides
+	for key, raw := range info.Overrides {
+		value := overrideValue(key, raw, params)
+		log.Trace().Msgf("Overriding %s with %v", key, value)
+		v.Set(key, value)
+	}
+
 	config := make(map[string]interface{})
 	err := v.Unmarshal(&config)
 	if err != nil {
@@ -449,35 +607,67 @@ func readTemplateConfig(configFile string) PuppetContentTemplateInfo {
 	return config
 }
 
-func renderFile(fileName string, vars interface{}) (string, error) {
-	tmpl, err := template.
-		New(filepath.Base(fileName)).
-		Funcs(
-			template.FuncMap{
-				"toClassName": func(itemName string) string {
-					return strings.Title(strings.ToLower(itemName))
-				},
-			},
-		).
-		ParseFiles(fileName)
+// renderOptions controls how template files are rendered
+type renderOptions struct {
+	// Strict makes a reference to a key missing from the data an error rather
+	// than rendering `<no value>`
+	Strict bool
+	// Partials maps the name of each partial available to the file to its path
+	Partials map[string]string
+}
 
+// renderFile renders a template file with the given data
+func renderFile(fileName string, vars interface{}, opts renderOptions) (string, error) {
+	tmpl, err := parseTemplateFile(fileName, opts)
 	if err != nil {
-		log.Error().Msgf("Error parsing config: %v", err)
 		return "", err
 	}
 
-	return process(tmpl, vars), nil
+	return process(tmpl, vars)
 }
 
-func process(t *template.Template, vars interface{}) string {
+// parseTemplateFile parses a template file along with any partials, so the
+// file can use them with `{{template "name" .}}`
+func parseTemplateFile(fileName string, opts renderOptions) (*template.Template, error) {
+	tmpl := template.New(filepath.Base(fileName)).Funcs(templateFuncs())
+	if opts.Strict {
+		tmpl.Option("missingkey=error")
+	}
+
+	names := make([]string, 0, len(opts.Partials))
+	for name := range opts.Partials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data, err := os.ReadFile(opts.Partials[name])
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tmpl.New(name).Parse(string(data)); err != nil {
+			log.Error().Msgf("Error parsing partial: %v", err)
+			return nil, err
+		}
+	}
+
+	tmpl, err := tmpl.ParseFiles(fileName)
+	if err != nil {
+		log.Error().Msgf("Error parsing config: %v", err)
+		return nil, err
+	}
+
+	return tmpl, nil
+}
+
+func process(t *template.Template, vars interface{}) (string, error) {
 	var tmplBytes bytes.Buffer
 
 	err := t.Execute(&tmplBytes, vars)
 	if err != nil {
-		log.Error().Msgf("Error parsing config: %v", err)
-		return ""
+		log.Error().Msgf("Error rendering template: %v", err)
+		return "", err
 	}
-	return tmplBytes.String()
+	return tmplBytes.String(), nil
 }
 
 func filterFiles(ss []PuppetContentTemplate, test func(PuppetContentTemplate) bool) (ret []PuppetContentTemplate) {
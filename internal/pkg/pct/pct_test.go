@@ -43,10 +43,30 @@ func TestDeploy(t *testing.T) {
 
 	tmpFile := filepath.Base(tmp)
 
+	invalidCache := t.TempDir()
+	invalidTemplate := installTestTemplate(t, invalidCache, "invalid-project", "0.1.0")
+	err := os.WriteFile(filepath.Join(invalidTemplate, TemplateConfigFileName), []byte(`---
+template:
+  id: invalid-project
+  type: project
+  version: 0.1.0
+  parameters:
+    - name: puppet_module.license
+      type: enum
+      default: GPL
+      values: [Apache-2.0, MIT]
+    - name: puppet_module.summary
+      required: true
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	tests := []struct {
-		name string
-		args args
-		want []string
+		name    string
+		args    args
+		want    []string
+		wantErr bool
 	}{
 		{
 			name: "deploy a project and return the correct new files",
@@ -168,22 +188,115 @@ func TestDeploy(t *testing.T) {
 				filepath.Join(tmp, "wibble.txt"),
 			},
 		},
+		{
+			name: "deploy a project with valid parameters",
+			args: args{
+				info: DeployInfo{
+					SelectedTemplate: "param-project",
+					TemplateCache:    "testdata/examples",
+					TargetOutputDir:  filepath.Join(tmp, "params"),
+					TargetName:       "woo",
+				},
+			},
+			want: []string{
+				filepath.Join(tmp, "params", "woo"),
+				filepath.Join(tmp, "params", "woo", "README.md"),
+			},
+		},
+		{
+			name: "deploy a project with invalid parameters writes nothing",
+			args: args{
+				info: DeployInfo{
+					SelectedTemplate: "invalid-project",
+					TemplateCache:    invalidCache,
+					TargetOutputDir:  filepath.Join(tmp, "invalid"),
+					TargetName:       "woo",
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := Deploy(tt.args.info); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Deploy() = %v, want %v", got, tt.want)
+			got, err := Deploy(tt.args.info)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Deploy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if paths := deployedPaths(got); !reflect.DeepEqual(paths, tt.want) {
+				t.Errorf("Deploy() = %v, want %v", paths, tt.want)
+			}
+			if tt.wantErr {
+				_, err := os.Stat(tt.args.info.TargetOutputDir)
+				assert.True(t, os.IsNotExist(err))
 			}
 		})
 	}
 }
 
+func TestDeployDryRun(t *testing.T) {
+	tmp := t.TempDir()
+	info := DeployInfo{
+		SelectedTemplate: "good-project",
+		TemplateCache:    "testdata/examples",
+		TargetOutputDir:  tmp,
+		TargetName:       "woo",
+		DryRun:           true,
+	}
+
+	got, err := Deploy(info)
+	assert.NoError(t, err)
+	assert.True(t, got.DryRun)
+	assert.Equal(t, []DeployedFile{
+		{Path: filepath.Join(tmp, "woo"), Action: ActionCreate, IsDirectory: true},
+		{Path: filepath.Join(tmp, "woo", "empty.txt"), Action: ActionCreate, Copied: true},
+		{Path: filepath.Join(tmp, "woo", "goodfile.txt"), Action: ActionCreate},
+	}, got.Files)
+	_, err = os.Stat(filepath.Join(tmp, "woo"))
+	assert.True(t, os.IsNotExist(err), "a dry run should not write anything")
+
+	info.DryRun = false
+	_, err = Deploy(info)
+	assert.NoError(t, err)
+
+	info.DryRun = true
+	got, err = Deploy(info)
+	assert.NoError(t, err)
+	for _, f := range got.Files {
+		assert.Equal(t, ActionUnchanged, f.Action, f.Path)
+	}
+
+	err = os.WriteFile(filepath.Join(tmp, "woo", "goodfile.txt"), []byte("edited"), 0644)
+	assert.NoError(t, err)
+	got, err = Deploy(info)
+	assert.NoError(t, err)
+	assert.Equal(t, ActionConflict, got.Files[2].Action)
+	content, _ := os.ReadFile(filepath.Join(tmp, "woo", "goodfile.txt"))
+	assert.Equal(t, "edited", string(content))
+}
+
+// deployedPaths returns the paths of the files in a DeployResult
+func deployedPaths(result DeployResult) []string {
+	var paths []string
+	for _, f := range result.Files {
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
+
 func TestGet(t *testing.T) {
 	type args struct {
 		templateCache    string
 		selectedTemplate string
 	}
+
+	versionedCache := t.TempDir()
+	installTestTemplate(t, versionedCache, "versioned", "0.2.0")
+	installTestTemplate(t, versionedCache, "versioned", "0.10.0")
+	installTestTemplate(t, versionedCache, "versioned", "0.1.0")
+
 	tests := []struct {
 		name    string
 		args    args
@@ -210,6 +323,42 @@ func TestGet(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "returns the highest version when no version is selected",
+			args: args{
+				templateCache:    versionedCache,
+				selectedTemplate: "versioned",
+			},
+			want: PuppetContentTemplate{
+				Id:      "versioned",
+				Type:    "project",
+				Display: "versioned",
+				Version: "0.10.0",
+			},
+			wantErr: false,
+		},
+		{
+			name: "returns the selected version",
+			args: args{
+				templateCache:    versionedCache,
+				selectedTemplate: "versioned@0.2.0",
+			},
+			want: PuppetContentTemplate{
+				Id:      "versioned",
+				Type:    "project",
+				Display: "versioned",
+				Version: "0.2.0",
+			},
+			wantErr: false,
+		},
+		{
+			name: "returns error for a version that is not installed",
+			args: args{
+				templateCache:    versionedCache,
+				selectedTemplate: "versioned@1.0.0",
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -225,13 +374,54 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestList(t *testing.T) {
+	templatePath := t.TempDir()
+	installTestTemplate(t, templatePath, "foo", "0.2.0")
+	installTestTemplate(t, templatePath, "foo", "0.10.0")
+	installTestTemplate(t, templatePath, "bar", "1.0.0")
+
+	tests := []struct {
+		name         string
+		templateName string
+		want         []string
+	}{
+		{
+			name: "lists every version with the highest marked as default",
+			want: []string{"bar@1.0.0 (default)", "foo@0.10.0 (default)", "foo@0.2.0"},
+		},
+		{
+			name:         "filters by template id",
+			templateName: "foo",
+			want:         []string{"foo@0.10.0 (default)", "foo@0.2.0"},
+		},
+		{
+			name:         "filters by template id and version",
+			templateName: "foo@0.2.0",
+			want:         []string{"foo@0.2.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpls, err := List(templatePath, tt.templateName)
+			assert.NoError(t, err)
+
+			var got []string
+			for _, tmpl := range tmpls {
+				ref := tmpl.Id + "@" + tmpl.Version
+				if tmpl.Default {
+					ref += " (default)"
+				}
+				got = append(got, ref)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func Test_createTemplateFile(t *testing.T) {
 	type args struct {
-		targetName   string
-		configFile   string
 		templateFile PuppetContentTemplateFileInfo
-		tmpl         PuppetContentTemplate
-		pdkInfo      PDKInfo
+		config       map[string]interface{}
 	}
 
 	tmp := t.TempDir()
@@ -239,24 +429,14 @@ func Test_createTemplateFile(t *testing.T) {
 	tests := []struct {
 		name    string
 		args    args
+		want    string
 		wantErr bool
 	}{
 		{
 			name: "",
 			args: args{
-				targetName: "foobar",
-				configFile: "testdata/examples/good-project/pct.yml",
-				pdkInfo: PDKInfo{
-					Version:   "0.1.0",
-					Commit:    "abc12345",
-					BuildDate: "2021/06/27",
-				},
-				tmpl: PuppetContentTemplate{
-					Type:    "project",
-					Display: "Good Project",
-					URL:     "https://github.com/puppetlabs/pct-good-project",
-					Version: "0.1.0",
-					Id:      "good-project",
+				config: map[string]interface{}{
+					"example_data": "wakka",
 				},
 				templateFile: PuppetContentTemplateFileInfo{
 					TemplatePath:   "testdata/examples/good-project/content/goodfile.txt.tmpl",
@@ -266,28 +446,36 @@ func Test_createTemplateFile(t *testing.T) {
 					IsDirectory:    false,
 				},
 			},
+			want:    "This is wakka data",
 			wantErr: false,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := createTemplateFile(tt.args.targetName, tt.args.configFile, tt.args.templateFile, tt.args.tmpl, tt.args.pdkInfo); (err != nil) != tt.wantErr {
+			f, err := renderTemplateFile(tt.args.templateFile, tt.args.config, renderOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := createTemplateFile(f); (err != nil) != tt.wantErr {
 				t.Errorf("createTemplateFile() error = %v, wantErr %v", err, tt.wantErr)
 			}
-			if _, err := os.Stat(tt.args.templateFile.TargetFilePath); err != nil {
+			got, err := os.ReadFile(tt.args.templateFile.TargetFilePath)
+			if err != nil {
 				t.Errorf("createTemplateFile() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			assert.Equal(t, tt.want, string(got))
 		})
 	}
 }
 
 func Test_processConfiguration(t *testing.T) {
 	type args struct {
-		projectName     string
-		configFile      string
-		projectTemplate string
-		tmpl            PuppetContentTemplate
-		pdkInfo         PDKInfo
+		projectName string
+		configFile  string
+		tmpl        PuppetContentTemplate
+		pdkInfo     PDKInfo
+		answers     map[string]interface{}
+		overrides   map[string]string
 	}
 	cwd, _ := os.Getwd()
 	hostName, _ := os.Hostname()
@@ -300,10 +488,9 @@ func Test_processConfiguration(t *testing.T) {
 		{
 			name: "with a valid config, returns a correct map interface",
 			args: args{
-				projectName:     "good-project",
-				configFile:      "testdata/examples/good-project/pct.yml",
-				projectTemplate: "",
-				tmpl:            PuppetContentTemplate{},
+				projectName: "good-project",
+				configFile:  "testdata/examples/good-project/pct.yml",
+				tmpl:        PuppetContentTemplate{},
 				pdkInfo: PDKInfo{
 					Version:   "0.1.0",
 					Commit:    "abc12345",
@@ -338,10 +525,9 @@ func Test_processConfiguration(t *testing.T) {
 		{
 			name: "with a non existant config, returns default config",
 			args: args{
-				projectName:     "good-project",
-				configFile:      "testdata/notthere/notthere/notthere.yml",
-				projectTemplate: "",
-				tmpl:            PuppetContentTemplate{},
+				projectName: "good-project",
+				configFile:  "testdata/notthere/notthere/notthere.yml",
+				tmpl:        PuppetContentTemplate{},
 				pdkInfo: PDKInfo{
 					Version:   "0.1.0",
 					Commit:    "abc12345",
@@ -363,10 +549,145 @@ func Test_processConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "with declared parameter defaults, overrides the machine defaults",
+			args: args{
+				projectName: "good-project",
+				configFile:  "testdata/notthere/notthere/notthere.yml",
+				tmpl: PuppetContentTemplate{
+					Parameters: []PuppetContentTemplateParameter{
+						{Name: "puppet_module.author", Type: ParameterTypeString, Default: "acme"},
+					},
+				},
+				pdkInfo: PDKInfo{
+					Version:   "0.1.0",
+					Commit:    "abc12345",
+					BuildDate: "2021/06/27",
+				},
+			},
+			want: map[string]interface{}{
+				"pct_name": "good-project",
+				"user":     u,
+				"cwd":      cwd,
+				"hostname": hostName,
+				"pdk": map[string]interface{}{
+					"build_date":  "2021/06/27",
+					"commit_hash": "abc12345",
+					"version":     "0.1.0",
+				},
+				"puppet_module": map[string]interface{}{
+					"author": "acme",
+				},
+			},
+		},
+		{
+			name: "with answers, overrides the template defaults",
+			args: args{
+				projectName: "good-project",
+				configFile:  "testdata/examples/good-project/pct.yml",
+				tmpl:        PuppetContentTemplate{},
+				pdkInfo: PDKInfo{
+					Version:   "0.1.0",
+					Commit:    "abc12345",
+					BuildDate: "2021/06/27",
+				},
+				answers: map[string]interface{}{
+					"puppet_module": map[string]interface{}{
+						"summary": "An answered summary",
+					},
+				},
+			},
+			want: map[string]interface{}{
+				"user":     u,
+				"cwd":      cwd,
+				"hostname": hostName,
+				"pct_name": "good-project",
+				"pdk": map[string]interface{}{
+					"build_date":  "2021/06/27",
+					"commit_hash": "abc12345",
+					"version":     "0.1.0",
+				},
+				"template": map[string]interface{}{
+					"type":    "project",
+					"display": "Good Project",
+					"url":     "https://github.com/puppetlabs/pct-good-project",
+					"version": "0.1.0",
+					"id":      "good-project",
+				},
+				"puppet_module": map[string]interface{}{
+					"author":  u,
+					"license": "Apache-2.0",
+					"version": "0.1.0",
+					"summary": "An answered summary",
+				},
+			},
+		},
+		{
+			name: "with overrides, overrides answers and coerces types",
+			args: args{
+				projectName: "good-project",
+				configFile:  "testdata/examples/good-project/pct.yml",
+				tmpl: PuppetContentTemplate{
+					Parameters: []PuppetContentTemplateParameter{
+						{Name: "puppet_module.version", Type: ParameterTypeString},
+					},
+				},
+				pdkInfo: PDKInfo{
+					Version:   "0.1.0",
+					Commit:    "abc12345",
+					BuildDate: "2021/06/27",
+				},
+				answers: map[string]interface{}{
+					"puppet_module": map[string]interface{}{
+						"summary": "An answered summary",
+					},
+				},
+				overrides: map[string]string{
+					"puppet_module.summary": "An overridden summary",
+					"puppet_module.version": "1.0",
+					"puppet_module.port":    "8080",
+					"ci.enabled":            "true",
+				},
+			},
+			want: map[string]interface{}{
+				"user":     u,
+				"cwd":      cwd,
+				"hostname": hostName,
+				"pct_name": "good-project",
+				"pdk": map[string]interface{}{
+					"build_date":  "2021/06/27",
+					"commit_hash": "abc12345",
+					"version":     "0.1.0",
+				},
+				"template": map[string]interface{}{
+					"type":    "project",
+					"display": "Good Project",
+					"url":     "https://github.com/puppetlabs/pct-good-project",
+					"version": "0.1.0",
+					"id":      "good-project",
+				},
+				"puppet_module": map[string]interface{}{
+					"author":  u,
+					"license": "Apache-2.0",
+					"version": "1.0",
+					"port":    8080,
+					"summary": "An overridden summary",
+				},
+				"ci": map[string]interface{}{
+					"enabled": true,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := processConfiguration(tt.args.projectName, tt.args.configFile, tt.args.projectTemplate, tt.args.tmpl, tt.args.pdkInfo)
+			info := DeployInfo{
+				TargetName: tt.args.projectName,
+				PdkInfo:    tt.args.pdkInfo,
+				Answers:    tt.args.answers,
+				Overrides:  tt.args.overrides,
+			}
+			got := processConfiguration(info, tt.args.configFile, tt.args.tmpl)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("got = %+v\nwant %+v\n", got, tt.want)
 			}
@@ -458,10 +779,19 @@ func Test_renderFile(t *testing.T) {
 			want: "",
 			err: true,
 		},
+		{
+			name: "returns an error if the template fails to execute",
+			args: args{
+				fileName: "testdata/examples/good-project/content/goodfile.txt.tmpl",
+				vars: "not a map",
+			},
+			want: "",
+			err: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := renderFile(tt.args.fileName, tt.args.vars)
+			got, err := renderFile(tt.args.fileName, tt.args.vars, renderOptions{})
 			if tt.err && err == nil {
 				t.Fail()
 			} else if !tt.err && got != tt.want {
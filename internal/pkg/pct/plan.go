@@ -0,0 +1,347 @@
+package pct
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v2"
+)
+
+// Actions describing what a deployment does to each target path
+const (
+	ActionCreate    = "create"
+	ActionOverwrite = "overwrite"
+	ActionUnchanged = "unchanged"
+	ActionConflict  = "conflict"
+	ActionSkip      = "skip"
+	ActionBackup    = "backup"
+	ActionRemove    = "remove"
+)
+
+// DeployedFile is a file or directory produced by a deployment along with the
+// action taken on the target path
+type DeployedFile struct {
+	Path        string `json:"path"`
+	Action      string `json:"action"`
+	IsDirectory bool   `json:"directory"`
+	Copied      bool   `json:"copied"`
+	Backup      string `json:"backup,omitempty"`
+}
+
+// DeployResult describes the outcome of Deploy
+type DeployResult struct {
+	Files  []DeployedFile
+	DryRun bool
+	// Hooks are the commands run after the files were deployed
+	Hooks []string
+}
+
+// deployFile is a file or directory a deployment produces, rendered in memory
+// before anything is written to disk
+type deployFile struct {
+	PuppetContentTemplateFileInfo
+	Content string
+	Action  string
+	// Backup is where an existing file is moved to by the backup action
+	Backup string
+}
+
+func (f deployFile) deployed() DeployedFile {
+	d := DeployedFile{
+		Path:        f.TargetFilePath,
+		Action:      f.Action,
+		IsDirectory: f.IsDirectory,
+		Copied:      f.IsCopy && !f.IsDirectory,
+	}
+	if f.Action == ActionBackup {
+		d.Backup = f.Backup
+	}
+	return d
+}
+
+// deployPlan holds everything a deployment will write, resolved and rendered
+type deployPlan struct {
+	Info     DeployInfo
+	Template templateEntry
+	Config   map[string]interface{}
+	Files    []deployFile
+	Hooks    []deployHook
+	// Manifest records the deployment in the output directory. It is written
+	// along with the files, but is never a conflict
+	Manifest deployFile
+}
+
+// writes returns every file the plan writes, including the manifest
+func (p deployPlan) writes() []deployFile {
+	return append(append([]deployFile{}, p.Files...), p.Manifest)
+}
+
+// planDeployment resolves the target of a deployment and the template data,
+// validates the data against the template parameters and renders every file
+// of the template's content in memory, without writing anything. The content
+// of any included templates is planned first, in order, with later templates
+// replacing the files of earlier ones. Files that fail to render are reported
+// together in a DeployError. Existing files that differ from the output are
+// resolved with the conflict policy; a ConflictError is only returned
+// alongside the plan, so a dry run can still report it
+func planDeployment(info DeployInfo) (deployPlan, error) {
+	log.Trace().Msgf("PDKInfo: %+v", info.PdkInfo)
+
+	if err := validateConflictPolicy(info.OnConflict); err != nil {
+		return deployPlan{}, err
+	}
+
+	entry, err := findTemplate(info.TemplateCache, info.SelectedTemplate)
+	if err != nil {
+		return deployPlan{}, err
+	}
+	log.Debug().Msgf("Template: %s", filepath.Join(entry.Path, TemplateConfigFileName))
+	tmpl := entry.Info
+	log.Trace().Msgf("Parsed: %+v", tmpl)
+
+	layers, err := resolveIncludes(info.TemplateCache, entry)
+	if err != nil {
+		return deployPlan{}, err
+	}
+
+	info = resolveTarget(info, tmpl.Template)
+	log.Debug().Msgf("Target Name: %s", info.TargetName)
+	log.Debug().Msgf("Target Output: %s", info.TargetOutputDir)
+
+	config := processLayeredConfiguration(info, layers)
+	err = validateParameters(layeredParameters(layers), config)
+	if err != nil {
+		return deployPlan{}, err
+	}
+
+	plan := deployPlan{
+		Info:     info,
+		Template: entry,
+		Config:   config,
+	}
+	plan.Hooks, err = planHooks(layers, info.TargetOutputDir, config)
+	if err != nil {
+		return deployPlan{}, err
+	}
+	partials, err := collectPartials(layers)
+	if err != nil {
+		return deployPlan{}, err
+	}
+
+	planned := make(map[string]int)
+	var failed []FileError
+	for _, layer := range layers {
+		strict := info.Strict || layer.Info.Template.Strict
+		templateFiles, errs, err := collectContent(layer, info.TargetOutputDir, config, strict)
+		if err != nil {
+			return deployPlan{}, err
+		}
+		failed = append(failed, errs...)
+
+		for _, templateFile := range templateFiles {
+			if strict && !templateFile.IsDirectory && !templateFile.IsCopy {
+				undefined, err := undefinedReferences(templateFile.TemplatePath, config)
+				if err != nil {
+					undefined = append(undefined, err)
+				}
+				for _, u := range undefined {
+					failed = append(failed, newFileError(templateFile, u))
+				}
+				if len(undefined) > 0 {
+					continue
+				}
+			}
+
+			f, err := renderTemplateFile(templateFile, config, renderOptions{Strict: strict, Partials: partials})
+			if err != nil {
+				failed = append(failed, newFileError(templateFile, err))
+				continue
+			}
+
+			if i, ok := planned[f.TargetFilePath]; ok {
+				log.Debug().Msgf("'%s' replaces '%s'", f.TemplatePath, plan.Files[i].TemplatePath)
+				plan.Files[i] = f
+				continue
+			}
+			planned[f.TargetFilePath] = len(plan.Files)
+			plan.Files = append(plan.Files, f)
+		}
+	}
+	if len(failed) > 0 {
+		return deployPlan{}, &DeployError{Files: failed}
+	}
+
+	if info.WriteAnswers {
+		f, err := renderAnswersFile(info.TargetOutputDir, config)
+		if err != nil {
+			return deployPlan{}, err
+		}
+		plan.Files = append(plan.Files, f)
+	}
+
+	conflictErr := applyConflictPolicy(plan.Files, info.OnConflict)
+	if _, conflict := conflictErr.(*ConflictError); conflictErr != nil && !conflict {
+		return deployPlan{}, conflictErr
+	}
+
+	plan.Manifest, err = renderManifest(plan, layers)
+	if err != nil {
+		return deployPlan{}, err
+	}
+	return plan, conflictErr
+}
+
+// collectContent walks the content directory of a template, resolving the
+// target path of each file and directory that isn't excluded by the
+// template's rules
+func collectContent(entry templateEntry, outputDir string, config map[string]interface{}, strict bool) ([]PuppetContentTemplateFileInfo, []FileError, error) {
+	tmpl := entry.Info.Template
+	contentDir := filepath.Join(entry.Path, "content")
+
+	excluded, err := excludedPaths(tmpl.Rules, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var templateFiles []PuppetContentTemplateFileInfo
+	var failed []FileError
+	err = filepath.WalkDir(contentDir, func(path string, info os.DirEntry, err error) error {
+		skip := func() error {
+			if info != nil && info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if err != nil {
+			failed = append(failed, newFileError(PuppetContentTemplateFileInfo{TemplatePath: path}, err))
+			return skip()
+		}
+
+		rel, _ := filepath.Rel(contentDir, path)
+		if rel != "." && matchesAny(rel, excluded) {
+			log.Debug().Msgf("Excluded by rule: %s", path)
+			return skip()
+		}
+
+		log.Trace().Msgf("Processing: %s", path)
+		targetFile, err := resolveTargetPath(outputDir, rel, config, strict)
+		if err != nil {
+			failed = append(failed, newFileError(PuppetContentTemplateFileInfo{TemplatePath: path}, err))
+			return skip()
+		}
+		if targetFile == "" {
+			log.Debug().Msgf("Skipped '%s' as its path rendered empty", path)
+			return skip()
+		}
+		log.Debug().Msgf("Resolved '%s' to '%s'", path, targetFile)
+
+		mode, err := contentMode(rel, info, tmpl.Modes)
+		if err != nil {
+			failed = append(failed, newFileError(PuppetContentTemplateFileInfo{TemplatePath: path}, err))
+			return skip()
+		}
+
+		dir, file := filepath.Split(targetFile)
+		i := PuppetContentTemplateFileInfo{
+			TemplatePath:   path,
+			TargetFilePath: targetFile,
+			TargetDir:      dir,
+			TargetFile:     file,
+			IsDirectory:    info.IsDir(),
+			IsCopy:         !strings.HasSuffix(path, ".tmpl") || matchesAny(rel, tmpl.CopyOnly),
+			Mode:           mode,
+		}
+		log.Trace().Msgf("Processed: %+v", i)
+
+		templateFiles = append(templateFiles, i)
+		return nil
+	})
+
+	return templateFiles, failed, err
+}
+
+// renderTemplateFile renders a template file in memory, or reads it as it is
+// when it is copied, and works out the action deploying it would take on the
+// target path
+func renderTemplateFile(templateFile PuppetContentTemplateFileInfo, config map[string]interface{}, opts renderOptions) (deployFile, error) {
+	f := deployFile{PuppetContentTemplateFileInfo: templateFile}
+	if templateFile.IsCopy && !templateFile.IsDirectory {
+		log.Trace().Msgf("Copying: '%s'", templateFile.TemplatePath)
+		data, err := os.ReadFile(templateFile.TemplatePath)
+		if err != nil {
+			return f, err
+		}
+		f.Content = string(data)
+	} else if !templateFile.IsDirectory {
+		log.Trace().Msgf("Rendering: '%s'", templateFile.TemplatePath)
+		text, err := renderFile(templateFile.TemplatePath, config, opts)
+		if err != nil {
+			return f, err
+		}
+		f.Content = text
+	}
+	f.Action = plannedAction(f)
+	return f, nil
+}
+
+// plannedAction compares a rendered file with the target path on disk. A file
+// whose content matches but which would change between being executable or
+// not is overwritten
+func plannedAction(f deployFile) string {
+	fi, err := os.Stat(f.TargetFilePath)
+	if err != nil {
+		return ActionCreate
+	}
+	if f.IsDirectory || fi.IsDir() {
+		if f.IsDirectory && fi.IsDir() {
+			return ActionUnchanged
+		}
+		return ActionOverwrite
+	}
+
+	existing, err := os.ReadFile(f.TargetFilePath)
+	if err == nil && bytes.Equal(existing, []byte(f.Content)) && !executableChanged(fi.Mode(), f.Mode) {
+		return ActionUnchanged
+	}
+	return ActionOverwrite
+}
+
+// executableChanged reports whether deploying a file with a mode would change
+// whether an existing file is executable. Other permission differences, such
+// as those from the umask, are ignored
+func executableChanged(existing os.FileMode, mode os.FileMode) bool {
+	return mode != 0 && existing.Perm()&0111 != mode.Perm()&0111
+}
+
+// renderAnswersFile renders the resolved template data as an answers file in
+// the output directory, so the deployment can be replayed with `--answers`.
+// The template metadata is left out as it belongs to the template, not the
+// answers
+func renderAnswersFile(targetDir string, config map[string]interface{}) (deployFile, error) {
+	answers := make(map[string]interface{})
+	for k, v := range config {
+		if k != "template" {
+			answers[k] = v
+		}
+	}
+
+	data, err := yaml.Marshal(answers)
+	if err != nil {
+		return deployFile{}, err
+	}
+
+	f := deployFile{
+		PuppetContentTemplateFileInfo: PuppetContentTemplateFileInfo{
+			TargetFilePath: filepath.Join(targetDir, AnswersFileName),
+			TargetDir:      targetDir,
+			TargetFile:     AnswersFileName,
+			Mode:           defaultFileMode,
+		},
+		Content: string(data),
+	}
+	f.Action = plannedAction(f)
+	return f, nil
+}
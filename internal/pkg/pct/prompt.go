@@ -0,0 +1,189 @@
+package pct
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PromptParameters walks through each parameter declared by the selected
+// template and the templates it includes, or each of its default values when
+// they declare none, showing the current value and reading a replacement from
+// in. An empty line keeps the current value. Variables set by
+// DeployInfo.Overrides are not prompted for. The answers given are returned
+// merged over any existing DeployInfo.Answers
+func PromptParameters(info DeployInfo, in io.Reader, out io.Writer) (map[string]interface{}, error) {
+	entry, err := findTemplate(info.TemplateCache, info.SelectedTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	layers, err := resolveIncludes(info.TemplateCache, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	info = resolveTarget(info, entry.Info.Template)
+	config := processLayeredConfiguration(info, layers)
+
+	params := layeredParameters(layers)
+	if len(params) == 0 {
+		params = parametersFromDefaults(entry.Info.Defaults)
+	}
+
+	answers := make(map[string]interface{})
+	reader := bufferedReader(in)
+	for _, p := range params {
+		if _, overridden := info.Overrides[strings.ToLower(p.Name)]; overridden {
+			continue
+		}
+
+		current, _ := lookupValue(config, p.Name)
+		for {
+			fmt.Fprint(out, promptText(p, current))
+
+			line, err := reader.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			eof := err == io.EOF
+			if eof {
+				fmt.Fprintln(out)
+			}
+
+			line = strings.TrimSpace(line)
+			if line == "" {
+				break
+			}
+
+			value, err := parseParameterValue(p, line)
+			if err == nil {
+				err = validateParameter(p, value)
+			}
+			if err == nil {
+				setValue(answers, p.Name, value)
+				break
+			}
+
+			fmt.Fprintf(out, "Invalid value for %s: %s\n", p.Name, err)
+			if eof {
+				return nil, fmt.Errorf("Invalid value for %s: %s", p.Name, err)
+			}
+		}
+	}
+
+	return mergeAnswers(info.Answers, answers), nil
+}
+
+// bufferedReader returns in as a *bufio.Reader, reusing it when it already is
+// one so that input read ahead by an earlier prompt isn't lost
+func bufferedReader(in io.Reader) *bufio.Reader {
+	if r, ok := in.(*bufio.Reader); ok {
+		return r
+	}
+	return bufio.NewReader(in)
+}
+
+func promptText(p PuppetContentTemplateParameter, current interface{}) string {
+	var b strings.Builder
+	b.WriteString(p.Name)
+	if p.Description != "" {
+		fmt.Fprintf(&b, " - %s", p.Description)
+	}
+	if c := describeConstraint(p); c != "" {
+		fmt.Fprintf(&b, " (%s)", c)
+	}
+	if current != nil {
+		fmt.Fprintf(&b, " [%v]", current)
+	}
+	b.WriteString(": ")
+	return b.String()
+}
+
+// parametersFromDefaults builds untyped parameter declarations for each value
+// in a template's defaults, inferring the type from the default value
+func parametersFromDefaults(defaults map[string]interface{}) []PuppetContentTemplateParameter {
+	var params []PuppetContentTemplateParameter
+	var walk func(prefix string, m map[string]interface{})
+	walk = func(prefix string, m map[string]interface{}) {
+		for k, v := range m {
+			name := k
+			if prefix != "" {
+				name = prefix + "." + k
+			}
+			if nested, ok := v.(map[string]interface{}); ok {
+				walk(name, nested)
+				continue
+			}
+			params = append(params, PuppetContentTemplateParameter{
+				Name:    name,
+				Type:    inferParameterType(v),
+				Default: v,
+			})
+		}
+	}
+	walk("", defaults)
+
+	sort.Slice(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+	return params
+}
+
+func inferParameterType(value interface{}) string {
+	if isInteger(value) {
+		return ParameterTypeInt
+	}
+	switch value.(type) {
+	case bool:
+		return ParameterTypeBool
+	case []interface{}, []string:
+		return ParameterTypeList
+	}
+	return ParameterTypeString
+}
+
+// parseParameterValue converts user input into the type declared by a
+// parameter. Lists are given as comma separated values
+func parseParameterValue(p PuppetContentTemplateParameter, input string) (interface{}, error) {
+	switch p.Type {
+	case ParameterTypeInt:
+		i, err := strconv.Atoi(input)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not an integer", input)
+		}
+		return i, nil
+	case ParameterTypeBool:
+		b, err := strconv.ParseBool(input)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a boolean", input)
+		}
+		return b, nil
+	case ParameterTypeList:
+		var list []interface{}
+		for _, item := range strings.Split(strings.Trim(input, "[]"), ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list, nil
+	}
+	return input, nil
+}
+
+// setValue sets a value in nested template data using a dotted key, creating
+// any intermediate maps
+func setValue(config map[string]interface{}, key string, value interface{}) {
+	parts := strings.Split(key, ".")
+	m := config
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := m[part].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			m[part] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = value
+}
@@ -0,0 +1,112 @@
+package pct
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromptParameters(t *testing.T) {
+	tests := []struct {
+		name       string
+		template   string
+		input      string
+		overrides  map[string]string
+		want       map[string]interface{}
+		wantOutput []string
+		wantErr    bool
+	}{
+		{
+			name:     "accepts the current values on empty input",
+			template: "param-project",
+			input:    "\n\n\n\n",
+			want:     map[string]interface{}{},
+			wantOutput: []string{
+				"puppet_module.summary - A one line summary of the module [A New Puppet Module]: ",
+				"puppet_module.license - The license the module is released under (one of: Apache-2.0, MIT) [Apache-2.0]: ",
+			},
+		},
+		{
+			name:     "overrides declared parameters with typed answers",
+			template: "param-project",
+			input:    "My module\nMIT\n1.2.3\nDebian, Windows\n",
+			want: map[string]interface{}{
+				"puppet_module": map[string]interface{}{
+					"summary":          "My module",
+					"license":          "MIT",
+					"version":          "1.2.3",
+					"operatingsystems": []interface{}{"Debian", "Windows"},
+				},
+			},
+		},
+		{
+			name:     "prompts again after an invalid answer",
+			template: "param-project",
+			input:    "\nGPL\nMIT\n",
+			want: map[string]interface{}{
+				"puppet_module": map[string]interface{}{
+					"license": "MIT",
+				},
+			},
+			wantOutput: []string{"Invalid value for puppet_module.license: 'GPL' is not one of: Apache-2.0, MIT"},
+		},
+		{
+			name:     "returns an error when input ends on an invalid answer",
+			template: "param-project",
+			input:    "\nGPL",
+			wantErr:  true,
+		},
+		{
+			name:      "does not prompt for overridden variables",
+			template:  "param-project",
+			input:     "MIT\n",
+			overrides: map[string]string{"puppet_module.summary": "Overridden", "puppet_module.version": "1.0.0"},
+			want: map[string]interface{}{
+				"puppet_module": map[string]interface{}{
+					"license": "MIT",
+				},
+			},
+		},
+		{
+			name:     "prompts for template defaults when no parameters are declared",
+			template: "full-project",
+			input:    "GPL-3.0\nA changed summary\n",
+			want: map[string]interface{}{
+				"puppet_module": map[string]interface{}{
+					"license": "GPL-3.0",
+					"summary": "A changed summary",
+				},
+			},
+			wantOutput: []string{
+				"puppet_module.license [Apache-2.0]: ",
+				"puppet_module.summary [A New Puppet Module]: ",
+				"puppet_module.version [0.1.0]: ",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got, err := PromptParameters(DeployInfo{
+				SelectedTemplate: tt.template,
+				TemplateCache:    "testdata/examples",
+				TargetOutputDir:  t.TempDir(),
+				Overrides:        tt.overrides,
+			}, strings.NewReader(tt.input), &out)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PromptParameters() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+			for _, w := range tt.wantOutput {
+				assert.Contains(t, out.String(), w)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package pct
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// PuppetContentTemplateRule includes the files and directories of a template's
+// content that match Path, a glob relative to the content directory, only
+// when the When expression is true. For example:
+//
+//	rules:
+//	  - path: .gitlab-ci.yml
+//	    when: eq .ci.provider "gitlab"
+type PuppetContentTemplateRule struct {
+	Path string `mapstructure:"path" json:"path"`
+	When string `mapstructure:"when" json:"when"`
+}
+
+// excludedPaths evaluates the rules of a template against the template data,
+// returning the globs of the rules whose condition is false
+func excludedPaths(rules []PuppetContentTemplateRule, config map[string]interface{}) ([]string, error) {
+	var excluded []string
+	for _, rule := range rules {
+		if _, err := filepath.Match(rule.Path, ""); err != nil || rule.Path == "" {
+			return nil, fmt.Errorf("Invalid rule path '%s': the path must be a glob relative to the content directory", rule.Path)
+		}
+
+		include, err := evaluateCondition(rule.When, config)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid rule for '%s': %s", rule.Path, err)
+		}
+		if !include {
+			excluded = append(excluded, filepath.ToSlash(rule.Path))
+		}
+	}
+	return excluded, nil
+}
+
+// evaluateCondition renders a condition, with or without surrounding braces,
+// as a template and interprets the output as a boolean. An undefined value is
+// false
+func evaluateCondition(condition string, config map[string]interface{}) (bool, error) {
+	if !strings.Contains(condition, "{{") {
+		condition = "{{ " + condition + " }}"
+	}
+	tmpl, err := template.New("when").Funcs(templateFuncs()).Parse(condition)
+	if err != nil {
+		return false, err
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, config); err != nil {
+		return false, err
+	}
+
+	result := strings.TrimSpace(out.String())
+	if result == "" || result == "<no value>" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(result)
+	if err != nil {
+		return false, fmt.Errorf("the condition evaluated to '%s' rather than true or false", result)
+	}
+	return b, nil
+}
+
+// matchesAny reports whether a path relative to the content directory matches
+// any of the globs, with or without its .tmpl extension
+func matchesAny(rel string, globs []string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, glob := range globs {
+		for _, candidate := range []string{rel, strings.TrimSuffix(rel, ".tmpl")} {
+			if matched, _ := filepath.Match(glob, candidate); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
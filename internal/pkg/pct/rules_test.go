@@ -0,0 +1,121 @@
+package pct
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployRules(t *testing.T) {
+	cache := t.TempDir()
+	dir := installTestTemplate(t, cache, "ci", "0.1.0")
+	config := `---
+template:
+  id: ci
+  type: project
+  version: 0.1.0
+  rules:
+    - path: .gitlab-ci.yml
+      when: eq .ci.provider "gitlab"
+    - path: .github
+      when: '{{ eq .ci.provider "github" }}'
+    - path: "*.md"
+      when: .ci.docs
+ci:
+  provider: gitlab
+`
+	files := map[string]string{
+		TemplateConfigFileName:                             config,
+		filepath.Join("content", ".gitlab-ci.yml.tmpl"):    "gitlab",
+		filepath.Join("content", ".github", "ci.yml.tmpl"): "github",
+		filepath.Join("content", "CI.md"):                  "docs",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name: "includes files whose rule is true",
+			want: []string{".gitlab-ci.yml", ".pct", "version.txt"},
+		},
+		{
+			name:      "excludes directories whose rule is false",
+			overrides: map[string]string{"ci.provider": "github", "ci.docs": "true"},
+			want:      []string{".github", ".pct", "CI.md", "version.txt"},
+		},
+		{
+			name:      "rejects a rule that isn't a boolean",
+			overrides: map[string]string{"ci.docs": "yes please"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			_, err := Deploy(DeployInfo{
+				SelectedTemplate: "ci",
+				TemplateCache:    cache,
+				TargetOutputDir:  tmp,
+				TargetName:       "woo",
+				Overrides:        tt.overrides,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Deploy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			entries, _ := os.ReadDir(filepath.Join(tmp, "woo"))
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Name())
+			}
+			sort.Strings(got)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_evaluateCondition(t *testing.T) {
+	config := map[string]interface{}{
+		"ci": map[string]interface{}{"provider": "gitlab", "enabled": true},
+	}
+	tests := []struct {
+		condition string
+		want      bool
+		wantErr   bool
+	}{
+		{condition: `eq .ci.provider "gitlab"`, want: true},
+		{condition: `{{ ne .ci.provider "gitlab" }}`, want: false},
+		{condition: `.ci.enabled`, want: true},
+		{condition: `.ci.missing`, want: false},
+		{condition: `has .ci.provider (list "github" "gitlab")`, want: true},
+		{condition: `.ci.provider`, wantErr: true},
+		{condition: `eq .ci.provider`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			got, err := evaluateCondition(tt.condition, config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("evaluateCondition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package pct
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// InitInfo describes a new template for InitTemplate to write
+type InitInfo struct {
+	Id   string
+	Type string
+	// TargetOutputDir is the directory to create the template in, as a
+	// directory named after its id. Defaults to the current working directory
+	TargetOutputDir string
+}
+
+// InitResult describes the template written by InitTemplate
+type InitResult struct {
+	Path  string
+	Files []string
+}
+
+// InitTemplate writes the skeleton of a new template: a pct-config.yml, a
+// content directory with an example file, a README describing how to use and
+// test the template, and a test fixture of answers along with the output
+// expected from them. The skeleton passes LintTemplate as it is written
+func InitTemplate(info InitInfo) (InitResult, error) {
+	if !templateIdPattern.MatchString(info.Id) {
+		return InitResult{}, fmt.Errorf("Invalid template id '%s': use only letters, numbers, hyphens and underscores", info.Id)
+	}
+	if !isTemplateType(info.Type) {
+		return InitResult{}, fmt.Errorf("Invalid template type '%s': must be one of: %s", info.Type, strings.Join(TemplateTypes, ", "))
+	}
+
+	outputDir := info.TargetOutputDir
+	if outputDir == "" {
+		outputDir, _ = osUtils.Getwd()
+	}
+	templateDir := filepath.Join(outputDir, info.Id)
+	if entries, err := os.ReadDir(templateDir); err == nil && len(entries) > 0 {
+		return InitResult{}, fmt.Errorf("'%s' already exists and is not empty", templateDir)
+	}
+
+	files := skeletonFiles(info.Id, info.Type)
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := InitResult{Path: templateDir}
+	for _, name := range names {
+		path := filepath.Join(templateDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return InitResult{}, fmt.Errorf("Unable to create '%s': %s", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(files[name]), defaultFileMode); err != nil {
+			return InitResult{}, fmt.Errorf("Unable to write '%s': %s", path, err)
+		}
+		result.Files = append(result.Files, path)
+	}
+	return result, nil
+}
+
+// skeletonFiles returns the content of each file of a new template, by its
+// slash separated path within the template
+func skeletonFiles(id string, templateType string) map[string]string {
+	// a project is generated into a directory of its own, while an item adds
+	// to existing content so its example file is named after the item
+	example := "README.md"
+	expected := "example/README.md"
+	if templateType == "item" {
+		example = "{{pct_name}}.md"
+		expected = "example.md"
+	}
+
+	return map[string]string{
+		TemplateConfigFileName: fmt.Sprintf(`---
+template:
+  id: %s
+  type: %s
+  display: %s
+  version: 0.1.0
+  # url: <url to the template repository>
+  parameters:
+    - name: summary
+      type: string
+      description: A one line summary of the generated content
+      default: A new %s
+
+summary: "A new %s"
+`, id, templateType, displayName(id), templateType, templateType),
+
+		"content/" + example + ".tmpl": `# {{ .pct_name }}
+
+{{ .summary }}
+`,
+
+		"README.md": fmt.Sprintf(`# %s
+
+A %s template for the Puppet Content Templates (PCT) tool.
+
+## Usage
+
+`+"```"+` bash
+pct install <path or git repository URL of this template>
+pct new %s --name <name>
+`+"```"+`
+
+## Structure
+
+- `+"`%s`"+` describes the template and the parameters it accepts
+- `+"`content/`"+` holds the files the template generates. Files ending in `+"`.tmpl`"+` are rendered with the template variables, such as `+"`{{ .pct_name }}`"+`, and file and folder names can use them too
+- `+"`testdata/`"+` holds answers to test the template with and the output expected from them
+
+## Testing
+
+Check the template for problems, then generate content from the answers in `+"`testdata/answers.yml`"+` and compare it with the expected output:
+
+`+"```"+` bash
+pct template validate .
+pct new %s --templatepath .. --name example --answers testdata/answers.yml --output /tmp/%s
+diff -r -x .pct /tmp/%s testdata/expected
+`+"```"+`
+
+Update `+"`testdata/expected`"+` whenever the content of the template changes.
+`, displayName(id), templateType, id, TemplateConfigFileName, id, id, id),
+
+		"testdata/answers.yml": `---
+summary: "Content generated for testing"
+`,
+
+		"testdata/expected/" + expected: `# example
+
+Content generated for testing
+`,
+	}
+}
+
+// displayName turns an id such as my-template into a display name such as
+// My Template
+func displayName(id string) string {
+	words := strings.FieldsFunc(id, func(r rune) bool { return r == '-' || r == '_' })
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,79 @@
+package pct
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitTemplate(t *testing.T) {
+	for _, templateType := range TemplateTypes {
+		t.Run(templateType, func(t *testing.T) {
+			tmp := t.TempDir()
+			got, err := InitTemplate(InitInfo{Id: "my-template", Type: templateType, TargetOutputDir: tmp})
+			assert.NoError(t, err)
+			assert.Equal(t, filepath.Join(tmp, "my-template"), got.Path)
+
+			info := readTemplateConfig(filepath.Join(got.Path, TemplateConfigFileName))
+			assert.Equal(t, "my-template", info.Template.Id)
+			assert.Equal(t, templateType, info.Template.Type)
+			assert.Equal(t, "My Template", info.Template.Display)
+			assert.Equal(t, "0.1.0", info.Template.Version)
+
+			problems, err := LintTemplate(got.Path)
+			assert.NoError(t, err)
+			assert.Empty(t, problems)
+
+			// the fixture answers render the expected output
+			answers, err := ReadAnswers(filepath.Join(got.Path, "testdata", "answers.yml"))
+			assert.NoError(t, err)
+			output := t.TempDir()
+			_, err = Deploy(DeployInfo{
+				SelectedTemplate: "my-template",
+				TemplateCache:    tmp,
+				TargetOutputDir:  output,
+				TargetName:       "example",
+				Answers:          answers,
+				Strict:           true,
+			})
+			assert.NoError(t, err)
+
+			expectedDir := filepath.Join(got.Path, "testdata", "expected")
+			err = filepath.Walk(expectedDir, func(path string, fi os.FileInfo, err error) error {
+				if err != nil || fi.IsDir() {
+					return err
+				}
+				rel, _ := filepath.Rel(expectedDir, path)
+				want, _ := os.ReadFile(path)
+				content, err := os.ReadFile(filepath.Join(output, rel))
+				assert.NoError(t, err)
+				assert.Equal(t, string(want), string(content), rel)
+				return nil
+			})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestInitTemplateErrors(t *testing.T) {
+	tmp := t.TempDir()
+
+	_, err := InitTemplate(InitInfo{Id: "my template", Type: "project", TargetOutputDir: tmp})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Invalid template id 'my template'")
+	}
+
+	_, err = InitTemplate(InitInfo{Id: "my-template", Type: "module", TargetOutputDir: tmp})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "must be one of: project, item")
+	}
+
+	_, err = InitTemplate(InitInfo{Id: "my-template", Type: "project", TargetOutputDir: tmp})
+	assert.NoError(t, err)
+	_, err = InitTemplate(InitInfo{Id: "my-template", Type: "item", TargetOutputDir: tmp})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "already exists and is not empty")
+	}
+}
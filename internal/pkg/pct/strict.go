@@ -0,0 +1,97 @@
+package pct
+
+import (
+	"fmt"
+	"strings"
+	"text/template/parse"
+)
+
+// undefinedReferences parses a template file and returns an error for every
+// reference to a variable that is missing from the template data, with the
+// position of the reference in the same form as text/template errors.
+// References relative to a changed dot, within range and with blocks, can't
+// be checked ahead of time and are left to the missingkey=error option
+func undefinedReferences(fileName string, vars map[string]interface{}) ([]error, error) {
+	tmpl, err := parseTemplateFile(fileName, renderOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if tmpl.Tree == nil {
+		return nil, nil
+	}
+
+	r := referenceWalker{tree: tmpl.Tree, vars: vars}
+	r.walk(tmpl.Tree.Root)
+	return r.undefined, nil
+}
+
+type referenceWalker struct {
+	tree      *parse.Tree
+	vars      map[string]interface{}
+	undefined []error
+}
+
+func (r *referenceWalker) walk(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			r.walk(child)
+		}
+	case *parse.ActionNode:
+		r.walkPipe(n.Pipe)
+	case *parse.IfNode:
+		r.walkPipe(n.Pipe)
+		r.walk(n.List)
+		r.walk(n.ElseList)
+	case *parse.RangeNode:
+		r.walkPipe(n.Pipe)
+		r.walk(n.ElseList)
+	case *parse.WithNode:
+		r.walkPipe(n.Pipe)
+		r.walk(n.ElseList)
+	case *parse.TemplateNode:
+		r.walkPipe(n.Pipe)
+	}
+}
+
+func (r *referenceWalker) walkPipe(pipe *parse.PipeNode) {
+	if pipe == nil {
+		return
+	}
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			switch a := arg.(type) {
+			case *parse.FieldNode:
+				r.check(a, a.Ident)
+			case *parse.VariableNode:
+				// only $ is known to refer to the template data
+				if a.Ident[0] == "$" && len(a.Ident) > 1 {
+					r.check(a, a.Ident[1:])
+				}
+			case *parse.PipeNode:
+				r.walkPipe(a)
+			}
+		}
+	}
+}
+
+func (r *referenceWalker) check(node parse.Node, ident []string) {
+	var current interface{} = r.vars
+	for _, key := range ident {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			// not map data, so leave it to the template engine
+			return
+		}
+		current, ok = m[key]
+		if !ok {
+			location, _ := r.tree.ErrorContext(node)
+			r.undefined = append(r.undefined, fmt.Errorf("template: %s: undefined variable .%s", location, strings.Join(ident, ".")))
+			return
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package pct
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployStrict(t *testing.T) {
+	content := "{{ .pct_name }} {{ .puppet_modul.license }}\n" +
+		"{{ if .missing }}{{ .also_missing }}{{ end }}{{ $.nope }}\n" +
+		"{{ range .items }}{{ .name }}{{ end }}\n"
+
+	tests := []struct {
+		name         string
+		strict       bool
+		configStrict bool
+		want         []FileError
+		wantContent  string
+	}{
+		{
+			name:        "renders missing variables as no value by default",
+			wantContent: "woo <no value>\n<no value>\nab\n",
+		},
+		{
+			name:   "reports every undefined variable with --strict",
+			strict: true,
+			want: []FileError{
+				{Line: 1, Column: 32, Message: "undefined variable .puppet_modul.license"},
+				{Line: 2, Column: 6, Message: "undefined variable .missing"},
+				{Line: 2, Column: 20, Message: "undefined variable .also_missing"},
+				{Line: 2, Column: 49, Message: "undefined variable .nope"},
+			},
+		},
+		{
+			name:         "reports every undefined variable when the template is strict",
+			configStrict: true,
+			want: []FileError{
+				{Line: 1, Column: 32, Message: "undefined variable .puppet_modul.license"},
+				{Line: 2, Column: 6, Message: "undefined variable .missing"},
+				{Line: 2, Column: 20, Message: "undefined variable .also_missing"},
+				{Line: 2, Column: 49, Message: "undefined variable .nope"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := t.TempDir()
+			dir := installTestTemplate(t, cache, "strict", "0.1.0")
+			config := "---\ntemplate:\n  id: strict\n  type: project\n  version: 0.1.0\n"
+			if tt.configStrict {
+				config += "  strict: true\n"
+			}
+			config += "items:\n  - name: a\n  - name: b\n"
+			if err := os.WriteFile(filepath.Join(dir, TemplateConfigFileName), []byte(config), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "content", "version.txt.tmpl"), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			tmp := t.TempDir()
+			_, err := Deploy(DeployInfo{
+				SelectedTemplate: "strict",
+				TemplateCache:    cache,
+				TargetOutputDir:  tmp,
+				TargetName:       "woo",
+				Strict:           tt.strict,
+			})
+			if tt.want == nil {
+				assert.NoError(t, err)
+				got, _ := os.ReadFile(filepath.Join(tmp, "woo", "version.txt"))
+				assert.Equal(t, tt.wantContent, string(got))
+				return
+			}
+
+			if assert.IsType(t, &DeployError{}, err) {
+				var got []FileError
+				for _, f := range err.(*DeployError).Files {
+					got = append(got, FileError{Line: f.Line, Column: f.Column, Message: f.Message})
+				}
+				assert.Equal(t, tt.want, got)
+			}
+			assertDirEntries(t, tmp, nil)
+		})
+	}
+
+	t.Run("fails on undefined variables within a range with --strict", func(t *testing.T) {
+		cache := t.TempDir()
+		dir := installTestTemplate(t, cache, "strict", "0.1.0")
+		config := "---\ntemplate:\n  id: strict\n  type: project\n  version: 0.1.0\nitems:\n  - name: a\n"
+		if err := os.WriteFile(filepath.Join(dir, TemplateConfigFileName), []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+		err := os.WriteFile(filepath.Join(dir, "content", "version.txt.tmpl"), []byte("{{ range .items }}{{ .nmae }}{{ end }}"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Deploy(DeployInfo{
+			SelectedTemplate: "strict",
+			TemplateCache:    cache,
+			TargetOutputDir:  t.TempDir(),
+			TargetName:       "woo",
+			Strict:           true,
+		})
+		if assert.IsType(t, &DeployError{}, err) {
+			assert.Contains(t, err.(*DeployError).Files[0].Message, `map has no entry for key "nmae"`)
+		}
+	})
+}
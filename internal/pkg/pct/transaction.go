@@ -0,0 +1,169 @@
+package pct
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+)
+
+// deployTransaction moves the files of a deployment into place from a staging
+// directory, recording how to undo each change so a failed deployment can be
+// rolled back, leaving the target as it was found
+type deployTransaction struct {
+	stagingDir string
+	staged     map[string]string
+	undo       []func() error
+}
+
+// stageDeployment writes every rendered file of a plan into a temporary
+// staging directory. The staging directory is created within the nearest
+// existing ancestor of the target, so files can be renamed into place
+func stageDeployment(plan deployPlan) (*deployTransaction, error) {
+	parent := nearestExistingDir(plan.Info.TargetOutputDir)
+	stagingDir, err := os.MkdirTemp(parent, ".pct-staging-")
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create a staging directory in '%s': %s", parent, err)
+	}
+	log.Trace().Msgf("Staging: %s", stagingDir)
+
+	tx := &deployTransaction{
+		stagingDir: stagingDir,
+		staged:     make(map[string]string),
+	}
+	for i, f := range plan.writes() {
+		if f.IsDirectory || f.Action == ActionSkip || f.Action == ActionUnchanged || f.Action == ActionRemove {
+			continue
+		}
+
+		staged := f
+		staged.TargetDir = filepath.Join(stagingDir, "files")
+		staged.TargetFile = strconv.Itoa(i)
+		staged.TargetFilePath = filepath.Join(staged.TargetDir, staged.TargetFile)
+		err := createTemplateFile(staged)
+		if err != nil {
+			tx.cleanup()
+			return nil, fmt.Errorf("Failed to stage %s: %s", f.TargetFilePath, err)
+		}
+		tx.staged[f.TargetFilePath] = staged.TargetFilePath
+	}
+
+	return tx, nil
+}
+
+// apply puts a planned file in place, moving any existing file aside first.
+// A file being removed is only moved aside
+func (tx *deployTransaction) apply(f deployFile) error {
+	if f.Action == ActionSkip || f.Action == ActionUnchanged {
+		return nil
+	}
+
+	if f.Action == ActionBackup || f.Action == ActionOverwrite || f.Action == ActionRemove {
+		aside := f.Backup
+		if f.Action == ActionBackup {
+			// never replace a file that appeared since the plan was made
+			if _, err := os.Lstat(aside); !os.IsNotExist(err) {
+				return fmt.Errorf("the backup '%s' already exists", aside)
+			}
+		} else {
+			aside = filepath.Join(tx.stagingDir, "replaced", strconv.Itoa(len(tx.undo)))
+			if err := tx.mkdirAll(filepath.Dir(aside), 0); err != nil {
+				return err
+			}
+		}
+		if err := tx.rename(f.TargetFilePath, aside); err != nil {
+			return err
+		}
+	}
+	if f.Action == ActionRemove {
+		return nil
+	}
+
+	if f.IsDirectory {
+		return tx.mkdirAll(f.TargetFilePath, f.Mode)
+	}
+
+	if err := tx.mkdirAll(filepath.Dir(f.TargetFilePath), 0); err != nil {
+		return err
+	}
+	return tx.rename(tx.staged[f.TargetFilePath], f.TargetFilePath)
+}
+
+// rename moves a file, recording how to move it back
+func (tx *deployTransaction) rename(from string, to string) error {
+	log.Trace().Msgf("Moving: '%s' to '%s'", from, to)
+	if err := os.Rename(from, to); err != nil {
+		return err
+	}
+	tx.undo = append(tx.undo, func() error {
+		return os.Rename(to, from)
+	})
+	return nil
+}
+
+// mkdirAll creates a directory and any missing parents, recording each one
+// created so it can be removed again. When the directory itself is created
+// and mode is set, it is given those permissions
+func (tx *deployTransaction) mkdirAll(dir string, mode os.FileMode) error {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		d := missing[i]
+		log.Trace().Msgf("Creating: '%s'", d)
+		if err := os.Mkdir(d, os.ModePerm); err != nil {
+			return err
+		}
+		tx.undo = append(tx.undo, func() error {
+			return os.Remove(d)
+		})
+	}
+
+	if len(missing) > 0 && mode != 0 {
+		return os.Chmod(dir, mode)
+	}
+	return nil
+}
+
+// rollback undoes every recorded change in reverse order and removes the
+// staging directory
+func (tx *deployTransaction) rollback() {
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		if err := tx.undo[i](); err != nil {
+			log.Error().Msgf("Rollback: %s", err)
+		}
+	}
+	tx.undo = nil
+	tx.cleanup()
+}
+
+// cleanup removes the staging directory
+func (tx *deployTransaction) cleanup() {
+	if err := os.RemoveAll(tx.stagingDir); err != nil {
+		log.Error().Msgf("Unable to remove the staging directory '%s': %s", tx.stagingDir, err)
+	}
+}
+
+// nearestExistingDir returns dir, or the closest of its parents that exists
+func nearestExistingDir(dir string) string {
+	for {
+		if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
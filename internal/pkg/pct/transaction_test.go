@@ -0,0 +1,101 @@
+package pct
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployRollback(t *testing.T) {
+	t.Run("writes nothing when a file fails to render", func(t *testing.T) {
+		cache := t.TempDir()
+		dir := installTestTemplate(t, cache, "broken", "0.1.0")
+		err := os.WriteFile(filepath.Join(dir, "content", "z-broken.txt.tmpl"), []byte("{{ .pct_name "), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tmp := t.TempDir()
+		_, err = Deploy(DeployInfo{
+			SelectedTemplate: "broken",
+			TemplateCache:    cache,
+			TargetOutputDir:  filepath.Join(tmp, "new", "dir"),
+			TargetName:       "woo",
+		})
+		assert.Error(t, err)
+		assertDirEntries(t, tmp, nil)
+	})
+
+	t.Run("restores an existing target when a file fails to move into place", func(t *testing.T) {
+		tmp := t.TempDir()
+		target := filepath.Join(tmp, "woo")
+		if err := os.MkdirAll(target, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(target, "empty.txt"), []byte("edited"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		applyFailingLastFile(t, DeployInfo{
+			SelectedTemplate: "good-project",
+			TemplateCache:    "testdata/examples",
+			TargetOutputDir:  tmp,
+			TargetName:       "woo",
+			OnConflict:       ConflictOverwrite,
+		})
+
+		assertDirEntries(t, tmp, []string{"woo"})
+		assertDirEntries(t, target, []string{"empty.txt"})
+		content, _ := os.ReadFile(filepath.Join(target, "empty.txt"))
+		assert.Equal(t, "edited", string(content))
+	})
+
+	t.Run("removes created directories when a file fails to move into place", func(t *testing.T) {
+		tmp := t.TempDir()
+		applyFailingLastFile(t, DeployInfo{
+			SelectedTemplate: "good-project",
+			TemplateCache:    "testdata/examples",
+			TargetOutputDir:  filepath.Join(tmp, "new", "dir"),
+			TargetName:       "woo",
+		})
+		assertDirEntries(t, tmp, nil)
+	})
+}
+
+// applyFailingLastFile stages a deployment and applies it with the last staged
+// file missing, so it fails and is rolled back
+func applyFailingLastFile(t *testing.T, info DeployInfo) {
+	plan, err := planDeployment(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := stageDeployment(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := plan.Files[len(plan.Files)-1]
+	if err := os.Remove(tx.staged[last.TargetFilePath]); err != nil {
+		t.Fatal(err)
+	}
+
+	var applyErr error
+	for _, f := range plan.Files {
+		if applyErr = tx.apply(f); applyErr != nil {
+			break
+		}
+	}
+	assert.Error(t, applyErr)
+	tx.rollback()
+}
+
+func assertDirEntries(t *testing.T, dir string, want []string) {
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	assert.Equal(t, want, got)
+}
@@ -0,0 +1,380 @@
+package pct
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/olekukonko/tablewriter"
+	"github.com/rs/zerolog/log"
+)
+
+// Statuses describing what an upgrade does to each file
+const (
+	UpgradeAdded     = "added"
+	UpgradeUpdated   = "updated"
+	UpgradeMerged    = "merged"
+	UpgradeConflict  = "conflict"
+	UpgradeKept      = "kept"
+	UpgradeUnchanged = "unchanged"
+	UpgradeRemoved   = "removed"
+)
+
+// UpgradeInfo describes content to upgrade to a newer version of the template
+// that generated it
+type UpgradeInfo struct {
+	// SelectedTemplate is the template to upgrade to, either an id or an
+	// <id>@<version> reference. It can be left empty when the content was
+	// generated by a single template, to upgrade to its highest installed
+	// version
+	SelectedTemplate string
+	TemplateCache    string
+	// TargetOutputDir is the directory holding the manifest written when
+	// the content was generated. Defaults to the current working directory
+	TargetOutputDir string
+	PdkInfo         PDKInfo
+	// Overrides are raw values by dotted key, as given by `pct upgrade --set`,
+	// which take precedence over the recorded template data
+	Overrides map[string]string
+	// DryRun works out the outcome for every file without writing anything
+	DryRun bool
+	// Strict fails the upgrade on any reference to an undefined variable
+	Strict bool
+}
+
+// UpgradedFile is a file of an upgrade along with what happened to it
+type UpgradedFile struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+}
+
+// UpgradeResult describes the outcome of Upgrade
+type UpgradeResult struct {
+	Template string         `json:"template"`
+	From     string         `json:"from"`
+	To       string         `json:"to"`
+	Files    []UpgradedFile `json:"files"`
+	DryRun   bool           `json:"dry_run"`
+}
+
+// Conflicts returns the paths of the files left with conflict markers
+func (r UpgradeResult) Conflicts() []string {
+	var paths []string
+	for _, f := range r.Files {
+		if f.Status == UpgradeConflict {
+			paths = append(paths, f.Path)
+		}
+	}
+	return paths
+}
+
+// Upgrade re-applies a newer version of a template to content it generated,
+// using the template data recorded in the manifest. The output of the
+// recorded version of the template is rendered again as the common ancestor
+// of a three-way merge between the current files and the output of the new
+// version: files changed only by the template are updated, files changed
+// only locally are kept, and files changed by both are merged, with conflict
+// markers where the changes overlap. Files the new version no longer
+// generates are removed unless they have been changed locally. When the
+// recorded version is no longer installed, the checksums in the manifest are
+// used to find the files that are unchanged, and any other file that differs
+// from the new output is a conflict. Like Deploy, either every change is made
+// or none are
+func Upgrade(info UpgradeInfo) (UpgradeResult, error) {
+	outputDir := info.TargetOutputDir
+	if outputDir == "" {
+		outputDir, _ = osUtils.Getwd()
+	}
+
+	recorded, err := recordedTemplate(outputDir, info.SelectedTemplate)
+	if err != nil {
+		return UpgradeResult{}, err
+	}
+	_, version := ParseTemplateReference(info.SelectedTemplate)
+	ref := recorded.Id
+	if version != "" {
+		ref = recorded.Id + "@" + version
+	}
+
+	// only the values the user supplied are recorded and replayed, so defaults
+	// changed by the new version take effect. An answers file written when the
+	// content was generated is kept up to date with the template data
+	_, writeAnswers := recorded.Files[AnswersFileName]
+	deployInfo := DeployInfo{
+		TemplateCache:   info.TemplateCache,
+		TargetOutputDir: outputDir,
+		PdkInfo:         info.PdkInfo,
+		Answers:         recorded.Variables,
+		WriteAnswers:    writeAnswers,
+		Strict:          info.Strict,
+		OnConflict:      ConflictOverwrite,
+	}
+
+	newInfo := deployInfo
+	newInfo.SelectedTemplate = ref
+	newInfo.Overrides = info.Overrides
+	newPlan, err := planDeployment(newInfo)
+	if err != nil {
+		return UpgradeResult{}, err
+	}
+
+	var oldPlan *deployPlan
+	oldRef := recorded.Id + "@" + recorded.Version
+	if _, err := findTemplate(info.TemplateCache, oldRef); err == nil {
+		oldInfo := deployInfo
+		oldInfo.SelectedTemplate = oldRef
+		plan, err := planDeployment(oldInfo)
+		if err != nil {
+			return UpgradeResult{}, fmt.Errorf("Unable to render '%s' as it was generated: %s", oldRef, err)
+		}
+		oldPlan = &plan
+	} else {
+		log.Warn().Msgf("'%s' is not installed, only files unchanged since they were generated can be upgraded without conflicts", oldRef)
+	}
+
+	u := upgrade{
+		outputDir: outputDir,
+		recorded:  recorded,
+		labels: mergeLabels{
+			Current: "current",
+			Base:    oldRef,
+			Other:   newPlan.Template.Info.Template.Id + "@" + newPlan.Template.Info.Template.Version,
+		},
+	}
+	u.planFiles(newPlan, oldPlan)
+
+	result := UpgradeResult{
+		Template: recorded.Id,
+		From:     recorded.Version,
+		To:       newPlan.Template.Info.Template.Version,
+		Files:    u.results,
+		DryRun:   info.DryRun,
+	}
+	if info.DryRun {
+		return result, nil
+	}
+
+	plan := deployPlan{Info: newInfo, Files: u.writes, Manifest: newPlan.Manifest}
+	tx, err := stageDeployment(plan)
+	if err != nil {
+		return UpgradeResult{}, err
+	}
+	for _, f := range plan.writes() {
+		log.Debug().Msgf("Upgrading: %s", f.TargetFilePath)
+		if err := tx.apply(f); err != nil {
+			tx.rollback()
+			return UpgradeResult{}, fmt.Errorf("Failed to upgrade %s, no changes were made: %s", f.TargetFilePath, err)
+		}
+	}
+	tx.cleanup()
+
+	return result, nil
+}
+
+// recordedTemplate returns the manifest entry of the template being upgraded
+func recordedTemplate(outputDir string, selectedTemplate string) (ManifestTemplate, error) {
+	m, err := ReadManifest(outputDir)
+	if err != nil {
+		return ManifestTemplate{}, err
+	}
+	if len(m.Templates) == 0 {
+		return ManifestTemplate{}, fmt.Errorf("'%s' has no %s, only content generated by `pct new` can be upgraded", outputDir, ManifestFileName)
+	}
+
+	if selectedTemplate == "" {
+		if len(m.Templates) > 1 {
+			var ids []string
+			for _, t := range m.Templates {
+				ids = append(ids, t.Id)
+			}
+			return ManifestTemplate{}, fmt.Errorf("'%s' was generated by more than one template, select one of: %s", outputDir, strings.Join(ids, ", "))
+		}
+		return m.Templates[0], nil
+	}
+
+	id, _ := ParseTemplateReference(selectedTemplate)
+	recorded, ok := m.Template(id)
+	if !ok {
+		return ManifestTemplate{}, fmt.Errorf("'%s' was not generated by the template '%s'", outputDir, id)
+	}
+	return recorded, nil
+}
+
+// upgrade works out what happens to each file of an upgrade
+type upgrade struct {
+	outputDir string
+	recorded  ManifestTemplate
+	labels    mergeLabels
+	results   []UpgradedFile
+	writes    []deployFile
+}
+
+// ancestor is the output of the recorded version of the template for a file.
+// Generated is set when the recorded version generated the file, and Known
+// when its output is known
+type ancestor struct {
+	Generated bool
+	Known     bool
+	Content   string
+}
+
+// planFiles works out the status of each file generated by either version of
+// the template, and the files to write or remove
+func (u *upgrade) planFiles(newPlan deployPlan, oldPlan *deployPlan) {
+	oldFiles := make(map[string]deployFile)
+	if oldPlan != nil {
+		for _, f := range oldPlan.Files {
+			if !f.IsDirectory {
+				oldFiles[f.TargetFilePath] = f
+			}
+		}
+	}
+	ancestorOf := func(target string, current []byte, exists bool) ancestor {
+		if oldPlan != nil {
+			f, ok := oldFiles[target]
+			return ancestor{Generated: ok, Known: ok, Content: f.Content}
+		}
+		sum, ok := u.recorded.Files[u.relative(target)]
+		if ok && exists && sum == checksum(current) {
+			return ancestor{Generated: true, Known: true, Content: string(current)}
+		}
+		return ancestor{Generated: ok}
+	}
+
+	planned := make(map[string]bool)
+	for _, f := range newPlan.Files {
+		planned[f.TargetFilePath] = true
+		if f.IsDirectory {
+			if f.Action == ActionCreate {
+				u.write(f, UpgradeAdded)
+			}
+			continue
+		}
+
+		current, err := os.ReadFile(f.TargetFilePath)
+		if err != nil {
+			if ancestorOf(f.TargetFilePath, nil, false).Generated {
+				// removed locally since it was generated
+				u.result(f.TargetFilePath, UpgradeKept)
+				continue
+			}
+			u.write(f, UpgradeAdded)
+			continue
+		}
+		if string(current) == f.Content {
+			u.result(f.TargetFilePath, UpgradeUnchanged)
+			continue
+		}
+
+		base := ancestorOf(f.TargetFilePath, current, true)
+		switch {
+		case base.Known && base.Content == string(current):
+			u.write(f, UpgradeUpdated)
+		case base.Known && base.Content == f.Content:
+			u.result(f.TargetFilePath, UpgradeKept)
+		case !utf8.Valid(current) || !utf8.ValidString(f.Content):
+			// binary files can't be merged, so the current file is kept
+			u.result(f.TargetFilePath, UpgradeConflict)
+		default:
+			merged, conflicts := merge3(base.Content, string(current), f.Content, u.labels)
+			f.Content = merged
+			if conflicts > 0 {
+				u.write(f, UpgradeConflict)
+			} else {
+				u.write(f, UpgradeMerged)
+			}
+		}
+	}
+
+	// files the new version no longer generates
+	var removed []string
+	if oldPlan != nil {
+		for _, f := range oldPlan.Files {
+			if !f.IsDirectory {
+				removed = append(removed, f.TargetFilePath)
+			}
+		}
+	} else {
+		for rel := range u.recorded.Files {
+			removed = append(removed, filepath.Join(u.outputDir, filepath.FromSlash(rel)))
+		}
+	}
+	for _, target := range removed {
+		// the answers file belongs to the user rather than the template
+		if planned[target] || u.relative(target) == AnswersFileName {
+			continue
+		}
+		current, err := os.ReadFile(target)
+		if err != nil {
+			continue
+		}
+		base := ancestorOf(target, current, true)
+		if base.Known && base.Content == string(current) {
+			u.remove(target)
+		} else {
+			u.result(target, UpgradeKept)
+		}
+	}
+}
+
+func (u *upgrade) relative(target string) string {
+	rel, _ := filepath.Rel(u.outputDir, target)
+	return filepath.ToSlash(rel)
+}
+
+func (u *upgrade) result(target string, status string) {
+	u.results = append(u.results, UpgradedFile{Path: target, Status: status})
+}
+
+func (u *upgrade) write(f deployFile, status string) {
+	f.Action = ActionCreate
+	if status != UpgradeAdded {
+		f.Action = ActionOverwrite
+	}
+	u.writes = append(u.writes, f)
+	u.result(f.TargetFilePath, status)
+}
+
+func (u *upgrade) remove(target string) {
+	u.writes = append(u.writes, deployFile{
+		PuppetContentTemplateFileInfo: PuppetContentTemplateFileInfo{TargetFilePath: target},
+		Action:                        ActionRemove,
+	})
+	u.result(target, UpgradeRemoved)
+}
+
+// FormatUpgrade displays the outcome of an upgrade in table or json format
+func FormatUpgrade(upgraded UpgradeResult, format string) error {
+	switch format {
+	case "table":
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Status", "Path"})
+		table.SetBorder(false)
+		counts := make(map[string]int)
+		for _, f := range upgraded.Files {
+			table.Append([]string{f.Status, f.Path})
+			counts[f.Status]++
+		}
+		table.Render()
+
+		var summary []string
+		for _, status := range []string{UpgradeAdded, UpgradeUpdated, UpgradeMerged, UpgradeConflict, UpgradeRemoved, UpgradeKept, UpgradeUnchanged} {
+			if counts[status] > 0 {
+				summary = append(summary, fmt.Sprintf("%d %s", counts[status], status))
+			}
+		}
+		verb := "Upgraded"
+		if upgraded.DryRun {
+			verb = "Would upgrade"
+		}
+		log.Info().Msgf("%s %s from %s to %s: %s", verb, upgraded.Template, upgraded.From, upgraded.To, strings.Join(summary, ", "))
+	case "json":
+		j := jsoniter.ConfigFastest
+		prettyJSON, _ := j.MarshalIndent(upgraded, "", "  ")
+		fmt.Printf("%s\n", prettyJSON)
+	}
+	return nil
+}
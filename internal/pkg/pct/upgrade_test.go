@@ -0,0 +1,213 @@
+package pct
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// deployForUpgrade installs two versions of a test template, deploys the
+// first and edits the output, returning the template cache and the output
+func deployForUpgrade(t *testing.T) (string, string) {
+	cache := t.TempDir()
+	installLayeredTemplate(t, cache, "mod", "0.1.0", "", "", map[string]string{
+		"README.md.tmpl":          "# {{ .pct_name }}\n",
+		"pristine.txt.tmpl":       "one\n",
+		"edited.txt.tmpl":         "a\nb\nc\n",
+		"conflict.txt.tmpl":       "x\n",
+		"dropped.txt.tmpl":        "old\n",
+		"dropped-edited.txt.tmpl": "old\n",
+	})
+	installLayeredTemplate(t, cache, "mod", "0.2.0", "", "", map[string]string{
+		"README.md.tmpl":    "# {{ .pct_name }}\n",
+		"pristine.txt.tmpl": "two\n",
+		"edited.txt.tmpl":   "a\nb\nC\n",
+		"conflict.txt.tmpl": "y\n",
+		"new.txt.tmpl":      "new {{ .greeting }}\n",
+	})
+
+	tmp := t.TempDir()
+	_, err := Deploy(DeployInfo{
+		SelectedTemplate: "mod@0.1.0",
+		TemplateCache:    cache,
+		TargetOutputDir:  tmp,
+		TargetName:       "woo",
+		Overrides:        map[string]string{"greeting": "hello"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(tmp, "woo")
+	edits := map[string]string{
+		"README.md":          "# woo\n\nMy module\n",
+		"edited.txt":         "A\nb\nc\n",
+		"conflict.txt":       "z\n",
+		"dropped-edited.txt": "mine\n",
+	}
+	for name, content := range edits {
+		if err := os.WriteFile(filepath.Join(output, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cache, output
+}
+
+func upgradeStatuses(output string, result UpgradeResult) map[string]string {
+	statuses := make(map[string]string)
+	for _, f := range result.Files {
+		rel, _ := filepath.Rel(output, f.Path)
+		statuses[rel] = f.Status
+	}
+	return statuses
+}
+
+func TestUpgrade(t *testing.T) {
+	cache, output := deployForUpgrade(t)
+
+	got, err := Upgrade(UpgradeInfo{TemplateCache: cache, TargetOutputDir: output})
+	assert.NoError(t, err)
+	assert.Equal(t, "mod", got.Template)
+	assert.Equal(t, "0.1.0", got.From)
+	assert.Equal(t, "0.2.0", got.To)
+	assert.Equal(t, map[string]string{
+		"README.md":          UpgradeKept,
+		"pristine.txt":       UpgradeUpdated,
+		"edited.txt":         UpgradeMerged,
+		"conflict.txt":       UpgradeConflict,
+		"new.txt":            UpgradeAdded,
+		"version.txt":        UpgradeUpdated,
+		"dropped.txt":        UpgradeRemoved,
+		"dropped-edited.txt": UpgradeKept,
+	}, upgradeStatuses(output, got))
+	assert.Equal(t, []string{filepath.Join(output, "conflict.txt")}, got.Conflicts())
+
+	want := map[string]string{
+		"README.md":          "# woo\n\nMy module\n",
+		"pristine.txt":       "two\n",
+		"edited.txt":         "A\nb\nC\n",
+		"conflict.txt":       "<<<<<<< current\nz\n||||||| mod@0.1.0\nx\n=======\ny\n>>>>>>> mod@0.2.0\n",
+		"new.txt":            "new hello\n",
+		"dropped-edited.txt": "mine\n",
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(output, name))
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(got), name)
+	}
+	_, err = os.Stat(filepath.Join(output, "dropped.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	m, err := ReadManifest(output)
+	assert.NoError(t, err)
+	recorded, _ := m.Template("mod")
+	assert.Equal(t, "0.2.0", recorded.Version)
+	assert.Equal(t, checksum([]byte("y\n")), recorded.Files["conflict.txt"])
+	assert.NotContains(t, recorded.Files, "dropped.txt")
+}
+
+func TestUpgradeDryRun(t *testing.T) {
+	cache, output := deployForUpgrade(t)
+
+	got, err := Upgrade(UpgradeInfo{TemplateCache: cache, TargetOutputDir: output, SelectedTemplate: "mod@0.2.0", DryRun: true})
+	assert.NoError(t, err)
+	assert.True(t, got.DryRun)
+	assert.Equal(t, UpgradeUpdated, upgradeStatuses(output, got)["pristine.txt"])
+
+	content, _ := os.ReadFile(filepath.Join(output, "pristine.txt"))
+	assert.Equal(t, "one\n", string(content))
+	m, _ := ReadManifest(output)
+	assert.Equal(t, "0.1.0", m.Templates[0].Version)
+}
+
+func TestUpgradeWithoutRecordedVersion(t *testing.T) {
+	cache, output := deployForUpgrade(t)
+	if err := os.RemoveAll(filepath.Join(cache, "mod", "0.1.0")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Upgrade(UpgradeInfo{TemplateCache: cache, TargetOutputDir: output})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"README.md":          UpgradeConflict,
+		"pristine.txt":       UpgradeUpdated,
+		"edited.txt":         UpgradeConflict,
+		"conflict.txt":       UpgradeConflict,
+		"new.txt":            UpgradeAdded,
+		"version.txt":        UpgradeUpdated,
+		"dropped.txt":        UpgradeRemoved,
+		"dropped-edited.txt": UpgradeKept,
+	}, upgradeStatuses(output, got))
+}
+
+func TestUpgradeAnswersFile(t *testing.T) {
+	for _, oldInstalled := range []bool{true, false} {
+		cache := t.TempDir()
+		installLayeredTemplate(t, cache, "mod", "0.1.0", "", "greeting: hello\n", map[string]string{"README.md.tmpl": "# {{ .pct_name }}\n"})
+		installLayeredTemplate(t, cache, "mod", "0.2.0", "", "greeting: hello\nfarewell: bye\n", map[string]string{"README.md.tmpl": "# {{ .pct_name }}\n"})
+
+		tmp := t.TempDir()
+		_, err := Deploy(DeployInfo{SelectedTemplate: "mod@0.1.0", TemplateCache: cache, TargetOutputDir: tmp, TargetName: "woo", WriteAnswers: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		output := filepath.Join(tmp, "woo")
+		if !oldInstalled {
+			if err := os.RemoveAll(filepath.Join(cache, "mod", "0.1.0")); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		got, err := Upgrade(UpgradeInfo{TemplateCache: cache, TargetOutputDir: output})
+		assert.NoError(t, err)
+		assert.Equal(t, UpgradeUpdated, upgradeStatuses(output, got)[AnswersFileName], "old version installed: %v", oldInstalled)
+
+		answers, err := ReadAnswers(filepath.Join(output, AnswersFileName))
+		assert.NoError(t, err)
+		assert.Equal(t, "bye", answers["farewell"])
+	}
+}
+
+func TestUpgradeChangedDefaults(t *testing.T) {
+	cache := t.TempDir()
+	files := map[string]string{"LICENSE.tmpl": "{{ .puppet_module.license }} {{ .puppet_module.summary }}\n"}
+	installLayeredTemplate(t, cache, "mod", "0.1.0", "", "puppet_module:\n  license: Apache-2.0\n  summary: old\n", files)
+	installLayeredTemplate(t, cache, "mod", "0.2.0", "", "puppet_module:\n  license: MIT\n  summary: new\n", files)
+
+	tmp := t.TempDir()
+	_, err := Deploy(DeployInfo{
+		SelectedTemplate: "mod@0.1.0",
+		TemplateCache:    cache,
+		TargetOutputDir:  tmp,
+		TargetName:       "woo",
+		Overrides:        map[string]string{"puppet_module.summary": "mine"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(tmp, "woo")
+
+	_, err = Upgrade(UpgradeInfo{TemplateCache: cache, TargetOutputDir: output})
+	assert.NoError(t, err)
+
+	// the new default is used, while the value the user gave is kept
+	content, _ := os.ReadFile(filepath.Join(output, "LICENSE"))
+	assert.Equal(t, "MIT mine\n", string(content))
+}
+
+func TestUpgradeErrors(t *testing.T) {
+	cache, output := deployForUpgrade(t)
+	installLayeredTemplate(t, cache, "ci", "0.1.0", "", "", nil)
+
+	_, err := Upgrade(UpgradeInfo{TemplateCache: cache, TargetOutputDir: t.TempDir()})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "only content generated by `pct new` can be upgraded")
+	}
+
+	_, err = Upgrade(UpgradeInfo{TemplateCache: cache, TargetOutputDir: output, SelectedTemplate: "ci"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "was not generated by the template 'ci'")
+	}
+}
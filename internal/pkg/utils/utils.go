@@ -2,11 +2,16 @@ package utils
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/puppetlabs/pdkgo/internal/pkg/pct"
 	"github.com/puppetlabs/pdkgo/internal/pkg/pdkshell"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
 )
 
 // contains checks if a string is present in a slice
@@ -78,3 +83,48 @@ func buildPDKCommandName(cmd *cobra.Command) []string {
 	}
 	return argsV
 }
+
+// GetDefaultTemplatePath returns the default location of installed templates,
+// which is a `templates` directory alongside the running executable
+func GetDefaultTemplatePath() (string, error) {
+	execDir, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+
+	defaultTemplatePath := filepath.Join(filepath.Dir(execDir), "templates")
+	log.Trace().Msgf("Default template path: %v", defaultTemplatePath)
+	return defaultTemplatePath, nil
+}
+
+// GetTemplatePath binds the `templatepath` flag of the given command and returns
+// the resulting location of installed templates, which falls back to the
+// default template location when neither the flag nor config set it
+func GetTemplatePath(cmd *cobra.Command) (string, error) {
+	viper.BindPFlag("templatepath", cmd.Flags().Lookup("templatepath")) //nolint:errcheck
+
+	defaultTemplatePath, err := GetDefaultTemplatePath()
+	if err != nil {
+		return "", err
+	}
+
+	viper.SetDefault("templatepath", defaultTemplatePath)
+	return viper.GetString("templatepath"), nil
+}
+
+// GetApplicationInfo parses the version, commit and build date from the first
+// line of the application version string, returning empty info when the string
+// does not contain all three
+func GetApplicationInfo(appVersionString string) pct.PDKInfo {
+	info := strings.Split(appVersionString, "\n")[0]
+	appInfo := strings.Split(info, " ")
+	if len(appInfo) < 4 {
+		return pct.PDKInfo{}
+	}
+
+	return pct.PDKInfo{
+		Version:   appInfo[1],
+		Commit:    appInfo[2],
+		BuildDate: appInfo[3],
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/puppetlabs/pdkgo/internal/pkg/pct"
 	"github.com/spf13/cobra"
 )
 
@@ -121,3 +122,34 @@ func TestGetListOfFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestGetApplicationInfo(t *testing.T) {
+	tests := []struct {
+		name             string
+		appVersionString string
+		want             pct.PDKInfo
+	}{
+		{
+			name:             "should parse the version, commit and build date",
+			appVersionString: "pct 0.1.0 abc1234 2021-04-01\nhttps://github.com/puppetlabs/pdkgo",
+			want:             pct.PDKInfo{Version: "0.1.0", Commit: "abc1234", BuildDate: "2021-04-01"},
+		},
+		{
+			name:             "should return empty info for an incomplete version string",
+			appVersionString: "pct 0.1.0",
+			want:             pct.PDKInfo{},
+		},
+		{
+			name:             "should return empty info for an empty version string",
+			appVersionString: "",
+			want:             pct.PDKInfo{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetApplicationInfo(tt.appVersionString); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetApplicationInfo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/puppetlabs/pdkgo/cmd/env"
 	"github.com/puppetlabs/pdkgo/cmd/get"
 	getConfig "github.com/puppetlabs/pdkgo/cmd/get/config"
+	"github.com/puppetlabs/pdkgo/cmd/install"
 	"github.com/puppetlabs/pdkgo/cmd/new"
 	"github.com/puppetlabs/pdkgo/cmd/release"
 	"github.com/puppetlabs/pdkgo/cmd/release/prep"
@@ -18,9 +19,16 @@ import (
 	"github.com/puppetlabs/pdkgo/cmd/root"
 	"github.com/puppetlabs/pdkgo/cmd/set"
 	setConfig "github.com/puppetlabs/pdkgo/cmd/set/config"
+	"github.com/puppetlabs/pdkgo/cmd/template"
+	templateInit "github.com/puppetlabs/pdkgo/cmd/template/init"
+	templatePath "github.com/puppetlabs/pdkgo/cmd/template/path"
+	templatePrune "github.com/puppetlabs/pdkgo/cmd/template/prune"
+	templateRemove "github.com/puppetlabs/pdkgo/cmd/template/remove"
+	templateValidate "github.com/puppetlabs/pdkgo/cmd/template/validate"
 	"github.com/puppetlabs/pdkgo/cmd/test"
 	"github.com/puppetlabs/pdkgo/cmd/test/unit"
 	"github.com/puppetlabs/pdkgo/cmd/update"
+	"github.com/puppetlabs/pdkgo/cmd/upgrade"
 	"github.com/puppetlabs/pdkgo/cmd/validate"
 	appver "github.com/puppetlabs/pdkgo/cmd/version"
 
@@ -75,6 +83,21 @@ func main() {
 	newCmd := new.CreateCommand()
 	rootCmd.AddCommand(newCmd)
 
+	rootCmd.AddCommand(install.CreateCommand())
+	rootCmd.AddCommand(upgrade.CreateCommand())
+
+	uninstallCmd := templateRemove.CreateCommand()
+	uninstallCmd.Use = "uninstall <id>[@version] [flags]"
+	rootCmd.AddCommand(uninstallCmd)
+
+	templateCmd := template.CreateCommand()
+	templateCmd.AddCommand(templateRemove.CreateCommand())
+	templateCmd.AddCommand(templatePrune.CreateCommand())
+	templateCmd.AddCommand(templatePath.CreateCommand())
+	templateCmd.AddCommand(templateValidate.CreateCommand())
+	templateCmd.AddCommand(templateInit.CreateCommand())
+	rootCmd.AddCommand(templateCmd)
+
 	rootCmd.AddCommand(bundle.CreateCommand())
 	rootCmd.AddCommand(console.CreateCommand())
 